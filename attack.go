@@ -0,0 +1,409 @@
+package hashcat
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/pixelsquared/go-hashcat/mask"
+	"github.com/pixelsquared/go-hashcat/models"
+)
+
+// Attack modes supported by hashcat
+const (
+	AttackModeStraight           = 0
+	AttackModeCombinator         = 1
+	AttackModeBruteForce         = 3
+	AttackModeHybridWordlistMask = 6
+	AttackModeHybridMaskWordlist = 7
+	AttackModeAssociation        = 9
+)
+
+// Attack describes a hashcat attack mode together with the inputs it needs
+type Attack interface {
+	// Mode returns the hashcat attack mode (--attack-mode)
+	Mode() int
+
+	// Validate checks that the attack has all the inputs it needs
+	Validate() error
+
+	// Options returns attack specific command-line options such as rules
+	Options() []string
+
+	// Arguments returns the positional arguments that follow the hash file
+	Arguments() []string
+}
+
+// StraightAttack runs every word of a wordlist, optionally through rules (-a 0)
+type StraightAttack struct {
+	Wordlist   string   // Path to the wordlist file or directory
+	RulesFiles []string // Rules files applied to each word (-r)
+}
+
+// Mode returns the hashcat attack mode
+func (a *StraightAttack) Mode() int {
+	return AttackModeStraight
+}
+
+// Validate checks that the wordlist and rules files exist
+func (a *StraightAttack) Validate() error {
+	if err := validateInputPath("wordlist", a.Wordlist); err != nil {
+		return err
+	}
+	return validateRulesFiles(a.RulesFiles)
+}
+
+// Options returns the rules options for the attack
+func (a *StraightAttack) Options() []string {
+	return rulesFileOptions(a.RulesFiles)
+}
+
+// Arguments returns the wordlist positional argument
+func (a *StraightAttack) Arguments() []string {
+	return []string{a.Wordlist}
+}
+
+// CombinatorAttack concatenates each word of the left wordlist with each word of the right wordlist (-a 1)
+type CombinatorAttack struct {
+	LeftWordlist  string // Path to the left wordlist
+	RightWordlist string // Path to the right wordlist
+	RuleLeft      string // Single rule applied to each word of the left wordlist (-j)
+	RuleRight     string // Single rule applied to each word of the right wordlist (-k)
+}
+
+// Mode returns the hashcat attack mode
+func (a *CombinatorAttack) Mode() int {
+	return AttackModeCombinator
+}
+
+// Validate checks that both wordlists exist
+func (a *CombinatorAttack) Validate() error {
+	if err := validateInputPath("left wordlist", a.LeftWordlist); err != nil {
+		return err
+	}
+	return validateInputPath("right wordlist", a.RightWordlist)
+}
+
+// Options returns the left and right rule options for the attack
+func (a *CombinatorAttack) Options() []string {
+	var options []string
+	if a.RuleLeft != "" {
+		options = append(options, fmt.Sprintf("--rule-left=%s", a.RuleLeft))
+	}
+	if a.RuleRight != "" {
+		options = append(options, fmt.Sprintf("--rule-right=%s", a.RuleRight))
+	}
+	return options
+}
+
+// Arguments returns the left and right wordlist positional arguments
+func (a *CombinatorAttack) Arguments() []string {
+	return []string{a.LeftWordlist, a.RightWordlist}
+}
+
+// BruteForceAttack tries every candidate described by a mask (-a 3)
+type BruteForceAttack struct {
+	Mask string // Mask describing the candidates, e.g. ?a?a?a?a
+}
+
+// Mode returns the hashcat attack mode
+func (a *BruteForceAttack) Mode() int {
+	return AttackModeBruteForce
+}
+
+// Validate checks that a mask was provided
+func (a *BruteForceAttack) Validate() error {
+	return validateMaskArgument(a.Mask)
+}
+
+// Options returns nil as brute-force attacks have no attack specific options
+func (a *BruteForceAttack) Options() []string {
+	return nil
+}
+
+// Arguments returns the mask positional argument
+func (a *BruteForceAttack) Arguments() []string {
+	return []string{a.Mask}
+}
+
+// maskEntries returns the mask of the attack
+func (a *BruteForceAttack) maskEntries() []mask.Entry {
+	return []mask.Entry{{Mask: a.Mask}}
+}
+
+// MaskListAttack runs a sequence of masks, each with its own custom charsets, as a single
+// brute-force attack (-a 3) by handing hashcat a .hcmask file
+type MaskListAttack struct {
+	Masks mask.List // Masks to run, in order
+	Path  string    // New file the masks are written to, which must not exist yet (a temporary file when empty)
+
+	file string // File written by prepare
+}
+
+// Mode returns the hashcat attack mode
+func (a *MaskListAttack) Mode() int {
+	return AttackModeBruteForce
+}
+
+// Validate checks that the list has at least one mask and that none of them are empty
+func (a *MaskListAttack) Validate() error {
+	if len(a.Masks) == 0 {
+		return fmt.Errorf("%w: mask list is empty", ErrInvalidAttack)
+	}
+
+	for _, entry := range a.Masks {
+		if err := validateMaskArgument(entry.Mask); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Options returns nil as the custom charsets are part of the mask file
+func (a *MaskListAttack) Options() []string {
+	return nil
+}
+
+// Arguments returns the mask file positional argument
+func (a *MaskListAttack) Arguments() []string {
+	if a.file != "" {
+		return []string{a.file}
+	}
+	return []string{a.Path}
+}
+
+// maskEntries returns the masks of the list
+func (a *MaskListAttack) maskEntries() []mask.Entry {
+	return a.Masks
+}
+
+// OverallPercent returns the completion of the whole mask sequence, weighting each mask
+// by its keyspace. hexCharset must match CrackOptions.HexCharset of the session. It falls
+// back to treating every mask equally when the progress does not line up with the list,
+// e.g. in increment mode.
+func (a *MaskListAttack) OverallPercent(progress *models.Progress, hexCharset bool) float64 {
+	offset := progress.Guess.GuessBaseOffset
+	count := progress.Guess.GuessBaseCount
+
+	if progress.Progress[1] == 0 {
+		return 0
+	}
+	percent := float64(progress.Progress[0]) / float64(progress.Progress[1]) * 100
+
+	keyspaces, err := a.Masks.Keyspaces(hexCharset)
+	if err != nil || count != len(keyspaces) || offset < 1 || offset > count {
+		if count > 1 && offset >= 1 {
+			return (float64(offset-1)*100 + percent) / float64(count)
+		}
+		return percent
+	}
+
+	done := new(big.Float)
+	total := new(big.Float)
+	for i, keyspace := range keyspaces {
+		k := new(big.Float).SetInt(keyspace)
+		total.Add(total, k)
+		if i < offset-1 {
+			done.Add(done, k)
+		}
+	}
+
+	// Credit the current mask with the fraction of its own keyspace that has been processed
+	current := new(big.Float).SetInt(keyspaces[offset-1])
+	current.Mul(current, big.NewFloat(percent/100))
+	done.Add(done, current)
+
+	overall, _ := new(big.Float).Quo(done, total).Float64()
+	return overall * 100
+}
+
+// prepare writes the mask list to its file
+func (a *MaskListAttack) prepare(dir, sessionName string) (string, error) {
+	var content strings.Builder
+	if _, err := a.Masks.WriteTo(&content); err != nil {
+		return "", fmt.Errorf("failed to write mask file: %w", err)
+	}
+
+	// Never overwrite a file the caller already has at Path
+	if a.Path != "" {
+		if err := writeNewFile(a.Path, content.String()); err != nil {
+			return "", fmt.Errorf("failed to write mask file: %w", err)
+		}
+		a.file = a.Path
+		return "", nil
+	}
+
+	file, err := createTempFile(dir, sessionName+"-masks-*.hcmask", content.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to create mask file: %w", err)
+	}
+	a.file = file
+
+	return file, nil
+}
+
+// HybridWordlistMaskAttack appends mask candidates to each word of a wordlist (-a 6)
+type HybridWordlistMaskAttack struct {
+	Wordlist string // Path to the wordlist
+	Mask     string // Mask appended to each word
+}
+
+// Mode returns the hashcat attack mode
+func (a *HybridWordlistMaskAttack) Mode() int {
+	return AttackModeHybridWordlistMask
+}
+
+// Validate checks that the wordlist exists and a mask was provided
+func (a *HybridWordlistMaskAttack) Validate() error {
+	if err := validateInputPath("wordlist", a.Wordlist); err != nil {
+		return err
+	}
+	return validateMaskArgument(a.Mask)
+}
+
+// Options returns nil as hybrid attacks have no attack specific options
+func (a *HybridWordlistMaskAttack) Options() []string {
+	return nil
+}
+
+// Arguments returns the wordlist and mask positional arguments
+func (a *HybridWordlistMaskAttack) Arguments() []string {
+	return []string{a.Wordlist, a.Mask}
+}
+
+// maskEntries returns the mask of the attack
+func (a *HybridWordlistMaskAttack) maskEntries() []mask.Entry {
+	return []mask.Entry{{Mask: a.Mask}}
+}
+
+// HybridMaskWordlistAttack prepends mask candidates to each word of a wordlist (-a 7)
+type HybridMaskWordlistAttack struct {
+	Mask     string // Mask prepended to each word
+	Wordlist string // Path to the wordlist
+}
+
+// Mode returns the hashcat attack mode
+func (a *HybridMaskWordlistAttack) Mode() int {
+	return AttackModeHybridMaskWordlist
+}
+
+// Validate checks that a mask was provided and the wordlist exists
+func (a *HybridMaskWordlistAttack) Validate() error {
+	if err := validateMaskArgument(a.Mask); err != nil {
+		return err
+	}
+	return validateInputPath("wordlist", a.Wordlist)
+}
+
+// Options returns nil as hybrid attacks have no attack specific options
+func (a *HybridMaskWordlistAttack) Options() []string {
+	return nil
+}
+
+// Arguments returns the mask and wordlist positional arguments
+func (a *HybridMaskWordlistAttack) Arguments() []string {
+	return []string{a.Mask, a.Wordlist}
+}
+
+// maskEntries returns the mask of the attack
+func (a *HybridMaskWordlistAttack) maskEntries() []mask.Entry {
+	return []mask.Entry{{Mask: a.Mask}}
+}
+
+// AssociationAttack tries each word of a wordlist against the hash on the same line of the hash file (-a 9)
+type AssociationAttack struct {
+	Wordlist   string   // Path to the wordlist, one candidate per hash
+	RulesFiles []string // Rules files applied to each word (-r)
+}
+
+// Mode returns the hashcat attack mode
+func (a *AssociationAttack) Mode() int {
+	return AttackModeAssociation
+}
+
+// Validate checks that the wordlist and rules files exist
+func (a *AssociationAttack) Validate() error {
+	if err := validateInputPath("wordlist", a.Wordlist); err != nil {
+		return err
+	}
+	return validateRulesFiles(a.RulesFiles)
+}
+
+// Options returns the rules options for the attack
+func (a *AssociationAttack) Options() []string {
+	return rulesFileOptions(a.RulesFiles)
+}
+
+// Arguments returns the wordlist positional argument
+func (a *AssociationAttack) Arguments() []string {
+	return []string{a.Wordlist}
+}
+
+// attackPreparer is implemented by attacks that need files written before hashcat starts.
+// prepare returns the path of any temporary file it created in dir so the session can remove it.
+type attackPreparer interface {
+	prepare(dir, sessionName string) (string, error)
+}
+
+// maskedAttack is implemented by attacks that generate candidates from masks
+type maskedAttack interface {
+	maskEntries() []mask.Entry
+}
+
+// NewAttack builds an Attack from an attack mode and its single positional argument.
+// Only the straight and brute-force modes can be expressed this way; the other modes
+// need more than one input and must be built from their attack types directly.
+func NewAttack(attackMode int, arg string) (Attack, error) {
+	switch attackMode {
+	case AttackModeStraight:
+		return &StraightAttack{Wordlist: arg}, nil
+	case AttackModeBruteForce:
+		return &BruteForceAttack{Mask: arg}, nil
+	case AttackModeCombinator, AttackModeHybridWordlistMask, AttackModeHybridMaskWordlist, AttackModeAssociation:
+		return nil, fmt.Errorf("%w: attack mode %d requires a typed attack", ErrInvalidAttack, attackMode)
+	default:
+		return nil, ErrInvalidAttackMode
+	}
+}
+
+// validateInputPath checks that a wordlist style input was provided and exists
+func validateInputPath(name, path string) error {
+	if path == "" {
+		return fmt.Errorf("%w: %s is required", ErrInvalidAttack, name)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("%w: %s not found: %v", ErrInvalidAttack, name, err)
+	}
+
+	return nil
+}
+
+// validateMaskArgument checks that a mask was provided
+func validateMaskArgument(mask string) error {
+	if mask == "" {
+		return fmt.Errorf("%w: mask is required", ErrInvalidAttack)
+	}
+	return nil
+}
+
+// validateRulesFiles checks that every rules file exists
+func validateRulesFiles(rulesFiles []string) error {
+	for _, rulesFile := range rulesFiles {
+		if err := validateInputPath("rules file", rulesFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rulesFileOptions renders the --rules-file option for each rules file
+func rulesFileOptions(rulesFiles []string) []string {
+	options := make([]string, 0, len(rulesFiles))
+	for _, rulesFile := range rulesFiles {
+		options = append(options, fmt.Sprintf("--rules-file=%s", rulesFile))
+	}
+	return options
+}
@@ -0,0 +1,137 @@
+package hashcat
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pixelsquared/go-hashcat/models"
+)
+
+// DeviceType is an OpenCL device type, selected with --opencl-device-types (-D)
+type DeviceType int
+
+const (
+	DeviceTypeCPU         DeviceType = 1 // CPUs
+	DeviceTypeGPU         DeviceType = 2 // GPUs
+	DeviceTypeAccelerator DeviceType = 3 // FPGAs, DSPs and co-processors
+)
+
+// String returns the name of the device type
+func (t DeviceType) String() string {
+	switch t {
+	case DeviceTypeCPU:
+		return "CPU"
+	case DeviceTypeGPU:
+		return "GPU"
+	case DeviceTypeAccelerator:
+		return "Accelerator"
+	}
+	return fmt.Sprintf("DeviceType(%d)", int(t))
+}
+
+// Validate checks that the device type is one hashcat knows
+func (t DeviceType) Validate() error {
+	switch t {
+	case DeviceTypeCPU, DeviceTypeGPU, DeviceTypeAccelerator:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown device type %d", ErrInvalidDevice, int(t))
+}
+
+// validateDeviceSelection checks backend device IDs, OpenCL device types and ignored
+// backends without listing the devices
+func validateDeviceSelection(ids []int, types []DeviceType, ignore []models.Backend) error {
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if id < 1 {
+			return fmt.Errorf("%w: device IDs start at 1, got %d", ErrInvalidDevice, id)
+		}
+		if seen[id] {
+			return fmt.Errorf("%w: device #%d is selected more than once", ErrInvalidDevice, id)
+		}
+		seen[id] = true
+	}
+
+	for _, deviceType := range types {
+		if err := deviceType.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, backend := range ignore {
+		if !backend.Valid() {
+			return fmt.Errorf("%w: unknown backend %q", ErrInvalidDevice, backend)
+		}
+	}
+
+	if ignoresAll(ignore) {
+		return fmt.Errorf("%w: every backend is ignored", ErrInvalidDevice)
+	}
+
+	return nil
+}
+
+// ignoresAll reports whether every supported backend is ignored
+func ignoresAll(ignore []models.Backend) bool {
+	for _, backend := range models.Backends {
+		found := false
+		for _, ignored := range ignore {
+			if ignored == backend {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// checkDeviceIDs checks that each backend device ID is listed by hashcat and is not
+// skipped, as hashcat does for aliases of devices listed under another backend
+func checkDeviceIDs(devices *models.DeviceList, ids []int) error {
+	for _, id := range ids {
+		device := FindDeviceByID(devices, id)
+		if device == nil {
+			return fmt.Errorf("%w: no backend device #%d", ErrInvalidDevice, id)
+		}
+
+		if device.Skipped {
+			if len(device.Aliases) > 0 {
+				return fmt.Errorf("%w: backend device #%d is skipped as an alias of #%d", ErrInvalidDevice, id, device.Aliases[0])
+			}
+			return fmt.Errorf("%w: backend device #%d is skipped", ErrInvalidDevice, id)
+		}
+	}
+	return nil
+}
+
+// deviceArgs returns the hashcat arguments selecting devices: --backend-devices (-d),
+// --opencl-device-types (-D) and a --backend-ignore-* flag for each ignored backend
+func deviceArgs(ids []int, types []DeviceType, ignore []models.Backend) []string {
+	var args []string
+
+	if len(ids) > 0 {
+		list := make([]string, len(ids))
+		for i, id := range ids {
+			list[i] = strconv.Itoa(id)
+		}
+		args = append(args, "--backend-devices="+strings.Join(list, ","))
+	}
+
+	if len(types) > 0 {
+		list := make([]string, len(types))
+		for i, deviceType := range types {
+			list[i] = strconv.Itoa(int(deviceType))
+		}
+		args = append(args, "--opencl-device-types="+strings.Join(list, ","))
+	}
+
+	for _, backend := range ignore {
+		args = append(args, "--backend-ignore-"+strings.ToLower(string(backend)))
+	}
+
+	return args
+}
@@ -1,59 +1,309 @@
 package hashcat
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/pixelsquared/go-hashcat/models"
 )
 
-// BenchmarkAll performs benchmarks for all supported hash types
+// BenchmarkOptions defines parameters for a benchmark run
+type BenchmarkOptions struct {
+	HashType        int  // Hash type to benchmark, unless All is set
+	All             bool // Benchmark every hash type in a single run (--benchmark-all)
+	Workload        int  // Workload profile (1=low, 2=default, 3=high, 4=nightmare, 0=hashcat default)
+	OptimizedKernel bool // Benchmark optimized kernels (-O) rather than pure kernels
+	MachineReadable bool // Read hashcat's --machine-readable output, which gives exact speeds in H/s but no hash names
+
+	// Device options
+	DeviceIDs      []int            // Backend device IDs to benchmark (-d, empty=all devices)
+	DeviceTypes    []DeviceType     // OpenCL device types to benchmark (-D, empty=hashcat default)
+	IgnoreBackends []models.Backend // Backends hashcat must not use (--backend-ignore-*)
+
+	// OnBenchmark is called with the results of each hash type as soon as every device
+	// has reported them, while hashcat moves on to the next one
+	OnBenchmark func(models.Benchmark) `json:"-"`
+}
+
+// Validate checks the options before the benchmark is run
+func (o *BenchmarkOptions) Validate() error {
+	if o.HashType < 0 {
+		return ErrInvalidHashType
+	}
+
+	if o.Workload < 0 || o.Workload > 4 {
+		return fmt.Errorf("%w: workload profile must be between 1 and 4", ErrInvalidCrackOptions)
+	}
+
+	return validateDeviceSelection(o.DeviceIDs, o.DeviceTypes, o.IgnoreBackends)
+}
+
+// args returns the hashcat arguments for the benchmark
+func (o *BenchmarkOptions) args() []string {
+	args := []string{"--benchmark", "--quiet"}
+
+	if o.All {
+		args = append(args, "--benchmark-all")
+	} else {
+		args = append(args, "--hash-type", strconv.Itoa(o.HashType))
+	}
+
+	if o.OptimizedKernel {
+		args = append(args, "--optimized-kernel-enable")
+	}
+	if o.Workload > 0 {
+		args = append(args, fmt.Sprintf("--workload-profile=%d", o.Workload))
+	}
+	if o.MachineReadable {
+		args = append(args, "--machine-readable")
+	}
+
+	return append(args, deviceArgs(o.DeviceIDs, o.DeviceTypes, o.IgnoreBackends)...)
+}
+
+// Benchmark performs a benchmark for the given hash type
+func (c *HashcatClient) Benchmark(ctx context.Context, hashType int) (*models.HashcatBenchmarkResponse, error) {
+	return c.BenchmarkWithOptions(ctx, &BenchmarkOptions{HashType: hashType})
+}
+
+// BenchmarkAll benchmarks every supported hash type in a single hashcat run. Use
+// BenchmarkWithOptions with All set to pass options or receive results as they complete.
 func (c *HashcatClient) BenchmarkAll(ctx context.Context) (*models.HashcatBenchmarkResponse, error) {
-	// Get all supported hash types first
-	hashes, err := c.GetSupportedHashes(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get supported hash types: %w", err)
+	return c.BenchmarkWithOptions(ctx, &BenchmarkOptions{All: true})
+}
+
+// BenchmarkWithOptions runs a benchmark with the given options and returns the results
+// of every hash type benchmarked, with a summary across all devices. When hashcat fails
+// partway through, the results of the hash types it completed are returned along with
+// the error.
+func (c *HashcatClient) BenchmarkWithOptions(ctx context.Context, options *BenchmarkOptions) (*models.HashcatBenchmarkResponse, error) {
+	if options == nil {
+		options = &BenchmarkOptions{}
+	}
+
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	if len(options.DeviceIDs) > 0 {
+		devices, err := c.GetDevices(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check devices: %w", err)
+		}
+		if err := checkDeviceIDs(devices, options.DeviceIDs); err != nil {
+			return nil, err
+		}
 	}
 
 	response := &models.HashcatBenchmarkResponse{
 		Benchmarks: []models.Benchmark{},
 	}
 
-	// Benchmark each hash type
-	for _, hashType := range hashes.HashTypes {
-		benchmarkResult, err := c.Benchmark(ctx, hashType.ID)
-		if err != nil {
-			// Log error but continue with other hash types
-			continue
+	parser := &benchmarkParser{
+		machineReadable: options.MachineReadable,
+		complete: func(benchmark models.Benchmark) {
+			response.Benchmarks = append(response.Benchmarks, benchmark)
+			if options.OnBenchmark != nil {
+				options.OnBenchmark(benchmark)
+			}
+		},
+	}
+
+	err := c.runBenchmark(ctx, options.args(), parser)
+
+	response.Summary = summarizeBenchmarks(response.Benchmarks)
+	if err != nil {
+		return response, fmt.Errorf("failed to run benchmark: %w", err)
+	}
+	return response, nil
+}
+
+// runBenchmark runs hashcat and feeds its output to the parser line by line as it is
+// printed. Failures are classified as for other commands.
+func (c *HashcatClient) runBenchmark(ctx context.Context, args []string, parser *benchmarkParser) error {
+	cmd := exec.CommandContext(ctx, c.config.BinaryPath, args...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrExecutionFailed, err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%w: %w", ErrExecutionFailed, err)
+	}
+
+	var messages outputClassifier
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if line := scanner.Text(); !parser.parse(line) {
+			messages.add(line)
 		}
+	}
+
+	// A truncated last mode is still reported when hashcat fails
+	parser.flush()
+
+	waitErr := cmd.Wait()
+	if waitErr == nil {
+		waitErr = scanner.Err()
+	}
+
+	messages.addOutput(stderr.String())
+	return classifyExit("benchmark", waitErr, &messages)
+}
+
+// Patterns for the benchmark output of hashcat:
+//
+//   - Hash-Mode 0 (MD5)
+//     Speed.#1.........: 52361.1 MH/s (51.23ms) @ Accel:1024 Loops:1024 Thr:64 Vec:8
+//
+// and for its machine readable form, device:mode:core clock:memory clock:ms:H/s:
+//
+//	1:0:1695:6000:51.23:52361100000
+var (
+	benchmarkModeRe  = regexp.MustCompile(`\* Hash-Mode (\d+) \((.+)\)`)
+	benchmarkSpeedRe = regexp.MustCompile(`Speed\.#(\d+)\.+:\s+([0-9.]+)\s+([kMGTP]?H/s).*?@\s+Accel:(\d+)\s+Loops:(\d+)\s+Thr:(\d+)\s+Vec:(\d+)`)
+	benchmarkTimeRe  = regexp.MustCompile(`\(([\d.]+)ms\)`)
+)
+
+// benchmarkParser groups the results hashcat prints for each device by hash type, and
+// reports each hash type once hashcat moves on to the next one
+type benchmarkParser struct {
+	machineReadable bool
+	current         *models.Benchmark
+	complete        func(models.Benchmark)
+}
+
+// parse reads a line of output and reports whether it was part of the results
+func (p *benchmarkParser) parse(line string) bool {
+	line = strings.TrimSpace(line)
+
+	if p.machineReadable {
+		// Lines starting with # tell the hashcat version and options
+		if strings.HasPrefix(line, "#") {
+			return true
+		}
+		return p.parseMachineReadable(line)
+	}
+
+	if matches := benchmarkModeRe.FindStringSubmatch(line); matches != nil {
+		hashMode, _ := strconv.Atoi(matches[1])
+		p.start(hashMode, matches[2])
+		return true
+	}
+
+	// The hash mode is underlined with dashes
+	if line != "" && strings.Trim(line, "-") == "" {
+		return true
+	}
+
+	matches := benchmarkSpeedRe.FindStringSubmatch(line)
+	if matches == nil || p.current == nil {
+		return false
+	}
+
+	result := models.BenchmarkResult{SpeedUnit: matches[3]}
+	result.DeviceID, _ = strconv.Atoi(matches[1])
+	result.Speed, _ = strconv.ParseFloat(matches[2], 64)
+	result.Acceleration, _ = strconv.Atoi(matches[4])
+	result.Loops, _ = strconv.Atoi(matches[5])
+	result.Threads, _ = strconv.Atoi(matches[6])
+	result.VectorSize, _ = strconv.Atoi(matches[7])
+
+	// Extract time per hash value (ms) if available
+	if timeMatches := benchmarkTimeRe.FindStringSubmatch(line); timeMatches != nil {
+		result.TimePerHash, _ = strconv.ParseFloat(timeMatches[1], 64)
+	}
+
+	p.current.DeviceResults = append(p.current.DeviceResults, result)
+	return true
+}
 
-		if len(benchmarkResult.Benchmarks) > 0 {
-			response.Benchmarks = append(response.Benchmarks, benchmarkResult.Benchmarks...)
+// parseMachineReadable reads a device:mode:core:memory:ms:speed line
+func (p *benchmarkParser) parseMachineReadable(line string) bool {
+	fields := strings.Split(line, ":")
+	if len(fields) < 6 {
+		return false
+	}
+
+	var values [6]float64
+	for i := range values {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return false
 		}
+		values[i] = value
 	}
 
-	// Calculate overall summary
+	hashMode := int(values[1])
+	if p.current == nil || p.current.HashMode != hashMode {
+		p.start(hashMode, "")
+	}
+
+	p.current.DeviceResults = append(p.current.DeviceResults, models.BenchmarkResult{
+		DeviceID:       int(values[0]),
+		CoreClockMHz:   int(values[2]),
+		MemoryClockMHz: int(values[3]),
+		TimePerHash:    values[4],
+		Speed:          values[5],
+		SpeedUnit:      "H/s",
+	})
+	return true
+}
+
+// start reports the current hash type and starts collecting the results of the next one
+func (p *benchmarkParser) start(hashMode int, hashName string) {
+	p.flush()
+	p.current = &models.Benchmark{
+		HashMode:      hashMode,
+		HashName:      hashName,
+		DeviceResults: []models.BenchmarkResult{},
+	}
+}
+
+// flush reports the current hash type, if it has results
+func (p *benchmarkParser) flush() {
+	if p.current != nil && len(p.current.DeviceResults) > 0 {
+		p.complete(*p.current)
+	}
+	p.current = nil
+}
+
+// speedUnits are the factors of the speed units hashcat prints, in H/s
+var speedUnits = map[string]float64{
+	"H/s":  1,
+	"kH/s": 1e3,
+	"MH/s": 1e6,
+	"GH/s": 1e9,
+	"TH/s": 1e12,
+	"PH/s": 1e15,
+}
+
+// summarizeBenchmarks sums the speed of every device across all hash types, in MH/s,
+// and averages the time per hash
+func summarizeBenchmarks(benchmarks []models.Benchmark) models.BenchmarkSummary {
 	var totalSpeed float64
 	var totalDevices int
 	var totalTimePerHash float64
 
-	// Use a common speed unit for the summary (MH/s)
-	speedUnit := "MH/s"
-
-	for _, benchmark := range response.Benchmarks {
+	for _, benchmark := range benchmarks {
 		for _, result := range benchmark.DeviceResults {
-			// Normalize speed to MH/s for consistent reporting
-			normalizedSpeed := result.Speed
-			switch result.SpeedUnit {
-			case "GH/s":
-				normalizedSpeed *= 1000
-			case "kH/s":
-				normalizedSpeed /= 1000
-			case "H/s":
-				normalizedSpeed /= 1000000
+			factor, ok := speedUnits[result.SpeedUnit]
+			if !ok {
+				factor = 1
 			}
 
-			totalSpeed += normalizedSpeed
+			// Normalize speed to MH/s for consistent reporting
+			totalSpeed += result.Speed * factor / 1e6
 			totalTimePerHash += result.TimePerHash
 			totalDevices++
 		}
@@ -65,11 +315,9 @@ func (c *HashcatClient) BenchmarkAll(ctx context.Context) (*models.HashcatBenchm
 		avgTimePerHash = totalTimePerHash / float64(totalDevices)
 	}
 
-	response.Summary = models.BenchmarkSummary{
+	return models.BenchmarkSummary{
 		TotalSpeed:     totalSpeed,
-		SpeedUnit:      speedUnit,
+		SpeedUnit:      "MH/s",
 		AvgTimePerHash: avgTimePerHash,
 	}
-
-	return response, nil
 }
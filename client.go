@@ -1,13 +1,10 @@
 package hashcat
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"os/exec"
-	"regexp"
 	"strconv"
-	"strings"
 
 	"github.com/pixelsquared/go-hashcat/models"
 )
@@ -17,18 +14,47 @@ type Client interface {
 	// GetDevices returns information about available devices
 	GetDevices(ctx context.Context) (*models.DeviceList, error)
 
+	// GetHardwareStatus returns the temperature, fan speed and utilization of each device
+	GetHardwareStatus(ctx context.Context) ([]models.DeviceTelemetry, error)
+
 	// GetSupportedHashes returns information about supported hash types
 	GetSupportedHashes(ctx context.Context) (*models.HashcatSupportedHashes, error)
 
 	// Benchmark performs a benchmark for the given hash type
 	Benchmark(ctx context.Context, hashType int) (*models.HashcatBenchmarkResponse, error)
 
+	// BenchmarkAll benchmarks every supported hash type in a single hashcat run
+	BenchmarkAll(ctx context.Context) (*models.HashcatBenchmarkResponse, error)
+
+	// BenchmarkWithOptions runs a benchmark with the given options, passing the results of
+	// each hash type to OnBenchmark as they complete. Completed results are returned even
+	// when hashcat fails.
+	BenchmarkWithOptions(ctx context.Context, options *BenchmarkOptions) (*models.HashcatBenchmarkResponse, error)
+
 	// Crack attempts to crack the provided hash using the specified attack mode and options
 	Crack(ctx context.Context, hash string, hashType int, attackMode int, mask string) (<-chan *models.Progress, error)
 
 	// CrackFile attempts to crack hashes in the specified file
 	CrackFile(ctx context.Context, hashFile *models.HashFile, attackMode int, mask string) (<-chan *models.Progress, error)
 
+	// CrackWithAttack attempts to crack the provided hash using a typed attack
+	CrackWithAttack(ctx context.Context, hash string, hashType int, attack Attack) (<-chan *models.Progress, error)
+
+	// CrackFileWithAttack attempts to crack hashes in the specified file using a typed attack
+	CrackFileWithAttack(ctx context.Context, hashFile *models.HashFile, attack Attack) (<-chan *models.Progress, error)
+
+	// Identify returns the hash types that match the given hashes, most likely first
+	Identify(ctx context.Context, hashes []string) ([]models.HashType, error)
+
+	// Show returns the hashes of a hash file that are already cracked in a potfile
+	Show(ctx context.Context, hashFile *models.HashFile, potfilePath string) ([]*models.CrackedHash, error)
+
+	// Left returns the hashes of a hash file that are not cracked in a potfile yet
+	Left(ctx context.Context, hashFile *models.HashFile, potfilePath string) ([]string, error)
+
+	// RestoreSession resumes an interrupted cracking session by name
+	RestoreSession(ctx context.Context, name string) (CrackSession, error)
+
 	// Stop interrupts a running cracking session
 	Stop(ctx context.Context) error
 }
@@ -95,30 +121,54 @@ func (c *HashcatClient) GetSupportedHashes(ctx context.Context) (*models.Hashcat
 	return hashTypes, nil
 }
 
-// Benchmark performs a benchmark for the given hash type
-func (c *HashcatClient) Benchmark(ctx context.Context, hashType int) (*models.HashcatBenchmarkResponse, error) {
-	args := []string{
-		"--hash-type", fmt.Sprintf("%d", hashType),
-		"--benchmark",
-		"--quiet",
-	}
+// HashInfo returns the metadata hashcat reports for a single hash type
+func (c *HashcatClient) HashInfo(ctx context.Context, hashType int) (*models.HashType, error) {
+	args := []string{"--machine-readable", "--hash-info", "--hash-type", strconv.Itoa(hashType), "--quiet"}
 
 	output, err := c.executeCommand(ctx, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to run benchmark: %w", err)
+		return nil, fmt.Errorf("failed to get hash type information: %w", err)
 	}
 
-	// Parse benchmark output
-	return parseBenchmarkOutput(output, hashType), nil
+	hashTypes, err := ParseHashInfoOutput(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse hash type information: %w", err)
+	}
+
+	info := FindHashTypeByID(hashTypes, hashType)
+	if info == nil {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidHashType, hashType)
+	}
+
+	return info, nil
 }
 
 // Crack attempts to crack the provided hash using the specified attack mode and options
 func (c *HashcatClient) Crack(ctx context.Context, hash string, hashType int, attackMode int, mask string) (<-chan *models.Progress, error) {
+	attack, err := NewAttack(attackMode, mask)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.CrackWithAttack(ctx, hash, hashType, attack)
+}
+
+// CrackFile attempts to crack hashes in the specified file
+func (c *HashcatClient) CrackFile(ctx context.Context, hashFile *models.HashFile, attackMode int, mask string) (<-chan *models.Progress, error) {
+	attack, err := NewAttack(attackMode, mask)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.CrackFileWithAttack(ctx, hashFile, attack)
+}
+
+// CrackWithAttack attempts to crack the provided hash using a typed attack
+func (c *HashcatClient) CrackWithAttack(ctx context.Context, hash string, hashType int, attack Attack) (<-chan *models.Progress, error) {
 	// Create options for the crack session
 	options := &CrackOptions{
 		HashType:        hashType,
-		AttackMode:      attackMode,
-		Mask:            mask,
+		Attack:          attack,
 		OptimizedKernel: true,
 	}
 
@@ -138,14 +188,14 @@ func (c *HashcatClient) Crack(ctx context.Context, hash string, hashType int, at
 	return session.Progress(), nil
 }
 
-// CrackFile attempts to crack hashes in the specified file
-func (c *HashcatClient) CrackFile(ctx context.Context, hashFile *models.HashFile, attackMode int, mask string) (<-chan *models.Progress, error) {
+// CrackFileWithAttack attempts to crack hashes in the specified file using a typed attack
+func (c *HashcatClient) CrackFileWithAttack(ctx context.Context, hashFile *models.HashFile, attack Attack) (<-chan *models.Progress, error) {
 	// Create options for the crack session
 	options := &CrackOptions{
 		HashType:        hashFile.HashType,
-		AttackMode:      attackMode,
-		Mask:            mask,
+		Attack:          attack,
 		OptimizedKernel: true,
+		Username:        hashFile.Username,
 	}
 
 	// Create a new crack file session
@@ -168,7 +218,10 @@ func (c *HashcatClient) CrackFile(ctx context.Context, hashFile *models.HashFile
 func (c *HashcatClient) Stop(ctx context.Context) error {
 	// First try to stop an active session if one exists
 	if c.activeSession != nil {
-		err := c.activeSession.Stop()
+		shutdownCtx, cancel := context.WithTimeout(ctx, c.config.StopTimeout)
+		defer cancel()
+
+		_, err := c.activeSession.Shutdown(shutdownCtx)
 		c.activeSession = nil
 		return err
 	}
@@ -181,82 +234,17 @@ func (c *HashcatClient) Stop(ctx context.Context) error {
 	return nil
 }
 
-// executeCommand is a helper method to execute hashcat commands
+// executeCommand is a helper method to execute hashcat commands. Failures are
+// classified from the exit code and output, as for crack sessions.
 func (c *HashcatClient) executeCommand(ctx context.Context, args ...string) (string, error) {
 	cmd := exec.CommandContext(ctx, c.config.BinaryPath, args...)
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return "", fmt.Errorf("command execution failed: %w", err)
+		var messages outputClassifier
+		messages.addOutput(string(output))
+		return "", classifyExit("command", err, &messages)
 	}
 
 	return string(output), nil
 }
-
-func parseBenchmarkOutput(output string, hashType int) *models.HashcatBenchmarkResponse {
-	scanner := bufio.NewScanner(strings.NewReader(output))
-
-	response := &models.HashcatBenchmarkResponse{
-		Benchmarks: []models.Benchmark{},
-		Summary:    models.BenchmarkSummary{},
-	}
-
-	hashModeFound := false
-
-	// Regular expressions for parsing
-	hashModeRegex := regexp.MustCompile(`\* Hash-Mode (\d+) \((.+)\)`)
-	speedRegex := regexp.MustCompile(`Speed\.#(\d+)\.+:\s+([0-9.]+)\s+([GMk]H/s).*?@\s+Accel:(\d+)\s+Loops:(\d+)\s+Thr:(\d+)\s+Vec:(\d+)`)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Check if line contains hash mode information
-		if matches := hashModeRegex.FindStringSubmatch(line); matches != nil {
-			hashMode, _ := strconv.Atoi(matches[1])
-			hashName := matches[2]
-
-			hashModeFound = true
-			benchmark := models.Benchmark{
-				HashMode:      hashMode,
-				HashName:      hashName,
-				DeviceResults: []models.BenchmarkResult{},
-			}
-			response.Benchmarks = append(response.Benchmarks, benchmark)
-		}
-
-		// Check if line contains speed information
-		if matches := speedRegex.FindStringSubmatch(line); matches != nil && hashModeFound {
-			deviceID, _ := strconv.Atoi(matches[1])
-			speed, _ := strconv.ParseFloat(matches[2], 64)
-			speedUnit := matches[3]
-			accel, _ := strconv.Atoi(matches[4])
-			loops, _ := strconv.Atoi(matches[5])
-			threads, _ := strconv.Atoi(matches[6])
-			vecSize, _ := strconv.Atoi(matches[7])
-
-			// Extract time per hash value (ms) if available
-			timePerHash := 0.0
-			timePerHashRegex := regexp.MustCompile(`\(([\d.]+)ms\)`)
-			if timeMatches := timePerHashRegex.FindStringSubmatch(line); timeMatches != nil {
-				timePerHash, _ = strconv.ParseFloat(timeMatches[1], 64)
-			}
-
-			result := models.BenchmarkResult{
-				DeviceID:     deviceID,
-				Speed:        speed,
-				SpeedUnit:    speedUnit,
-				TimePerHash:  timePerHash,
-				Acceleration: accel,
-				Loops:        loops,
-				Threads:      threads,
-				VectorSize:   vecSize,
-			}
-
-			// Add result to the last benchmark
-			if len(response.Benchmarks) > 0 {
-				response.Benchmarks[len(response.Benchmarks)-1].DeviceResults = append(response.Benchmarks[len(response.Benchmarks)-1].DeviceResults, result)
-			}
-		}
-	}
-	return response
-}
@@ -11,12 +11,16 @@ import (
 	"time"
 
 	"github.com/pixelsquared/go-hashcat"
+	"github.com/pixelsquared/go-hashcat/models"
 )
 
 func main() {
 	// Parse command line arguments
 	hashTypeArg := flag.Int("hash", 0, "Hash type ID to benchmark (default: 0 = MD5)")
 	listFlag := flag.Bool("list", false, "List common hash types and exit")
+	allFlag := flag.Bool("all", false, "Benchmark every hash type in a single run")
+	workload := flag.Int("w", 0, "Workload profile (1=low, 2=default, 3=high, 4=nightmare)")
+	optimized := flag.Bool("O", false, "Benchmark optimized kernels instead of pure kernels")
 	flag.Parse()
 
 	// Create hashcat client
@@ -31,6 +35,18 @@ func main() {
 		return
 	}
 
+	options := &hashcat.BenchmarkOptions{
+		HashType:        *hashTypeArg,
+		Workload:        *workload,
+		OptimizedKernel: *optimized,
+	}
+
+	// Print each hash type as soon as hashcat is done with it
+	if *allFlag {
+		benchmarkAll(client, options)
+		return
+	}
+
 	hashType := *hashTypeArg
 
 	// Get hash type name for display
@@ -41,7 +57,7 @@ func main() {
 
 	// Start benchmark
 	startTime := time.Now()
-	benchmark, err := client.Benchmark(context.Background(), hashType)
+	benchmark, err := client.BenchmarkWithOptions(context.Background(), options)
 	duration := time.Since(startTime)
 
 	if err != nil {
@@ -136,6 +152,31 @@ func main() {
 	}
 }
 
+// benchmarkAll benchmarks every hash type, printing the results of each one as they complete
+func benchmarkAll(client *hashcat.HashcatClient, options *hashcat.BenchmarkOptions) {
+	fmt.Println("Benchmarking every hash type, this may take a long time...")
+
+	options.All = true
+	options.OnBenchmark = func(b models.Benchmark) {
+		for _, result := range b.DeviceResults {
+			fmt.Printf("%6d  %-40s  Device #%d  %10.2f %s\n",
+				b.HashMode, b.HashName, result.DeviceID, result.Speed, result.SpeedUnit)
+		}
+	}
+
+	// Hash types completed before a failure are still reported
+	all, err := client.BenchmarkWithOptions(context.Background(), options)
+	if err != nil {
+		if all == nil || len(all.Benchmarks) == 0 {
+			log.Fatalf("Benchmark failed: %v", err)
+		}
+		log.Printf("Benchmark stopped early: %v", err)
+	}
+
+	fmt.Printf("\nBenchmarked %d hash types, total speed: %.2f %s\n",
+		len(all.Benchmarks), all.Summary.TotalSpeed, all.Summary.SpeedUnit)
+}
+
 // getHashTypeName returns the name of a hash type given its ID
 func getHashTypeName(client hashcat.Client, hashType int) string {
 	hashTypes, err := client.GetSupportedHashes(context.Background())
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -165,9 +166,17 @@ func main() {
 	// Add newline after progress output
 	fmt.Println()
 
-	// Wait for completion and get results
+	// Wait for completion and get results. Running out of candidates or being
+	// interrupted still leaves results to print.
 	if err := session.Wait(); err != nil {
-		log.Fatalf("Error during cracking: %v", err)
+		switch {
+		case errors.Is(err, hashcat.ErrExhausted):
+			fmt.Println("Keyspace exhausted.")
+		case hashcat.IsStopOutcome(err):
+			fmt.Println("Cracking was stopped.")
+		default:
+			log.Fatalf("Error during cracking: %v", err)
+		}
 	}
 
 	results, err := session.Results()
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -23,6 +24,9 @@ func main() {
 	fmt.Println("Retrieving device information...")
 	devices, err := client.GetDevices(context.Background())
 	if err != nil {
+		if errors.Is(err, hashcat.ErrNoDevices) {
+			log.Fatalf("hashcat found no usable devices, check the installed drivers: %v", err)
+		}
 		log.Fatalf("Error retrieving device information: %v", err)
 	}
 
@@ -33,7 +37,7 @@ func main() {
 
 	// Print platform and device information
 	for i, platform := range devices.Platforms {
-		fmt.Printf("\nPlatform #%d: %s\n", platform.ID, platform.Name)
+		fmt.Printf("\nPlatform #%d: %s (%s)\n", platform.ID, platform.Name, platform.Backend)
 		fmt.Printf("  Vendor:  %s\n", platform.Vendor)
 		fmt.Printf("  Version: %s\n", platform.Version)
 
@@ -44,17 +48,27 @@ func main() {
 
 		fmt.Println("\n  Devices:")
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-		fmt.Fprintln(w, "    ID\tName\tType\tProcessors\tClock\tMemory")
-		fmt.Fprintln(w, "    --\t----\t----\t----------\t-----\t------")
+		fmt.Fprintln(w, "    ID\tName\tType\tProcessors\tClock\tMemory\tPCI\tStatus")
+		fmt.Fprintln(w, "    --\t----\t----\t----------\t-----\t------\t---\t------")
 
 		for _, device := range platform.Devices {
-			fmt.Fprintf(w, "    %d\t%s\t%s\t%d\t%d MHz\t%d MB\n",
+			status := "active"
+			if device.Skipped {
+				status = "skipped"
+			}
+			if len(device.Aliases) > 0 {
+				status += fmt.Sprintf(" (alias of #%d)", device.Aliases[0])
+			}
+
+			fmt.Fprintf(w, "    %d\t%s\t%s\t%d\t%d MHz\t%d MB\t%s\t%s\n",
 				device.ID,
 				device.Name,
 				device.Type,
 				device.Processors,
 				device.ClockMHz,
-				device.MemoryTotal)
+				device.MemoryTotal,
+				device.PCIAddress,
+				status)
 		}
 		w.Flush()
 
@@ -94,6 +108,7 @@ func main() {
 	}
 
 	fmt.Printf("  Total platforms: %d\n", len(devices.Platforms))
+	fmt.Printf("  Backends:        %v\n", devices.Backends())
 	fmt.Printf("  Total devices:   %d\n", totalDevices)
 	fmt.Printf("  GPU devices:     %d\n", gpuDevices)
 	fmt.Printf("  CPU devices:     %d\n", cpuDevices)
@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"sort"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/pixelsquared/go-hashcat"
@@ -28,8 +29,8 @@ func main() {
 
 	// Create a tabwriter for nice formatting
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(w, "ID\tName\tCategory")
-	fmt.Fprintln(w, "----\t----\t--------")
+	fmt.Fprintln(w, "ID\tName\tCategory\tSlow\tSalted\tMax Len\tKernels")
+	fmt.Fprintln(w, "----\t----\t--------\t----\t------\t-------\t-------")
 
 	// Sort hash types by ID for consistent output
 	sort.Slice(hashTypes.HashTypes, func(i, j int) bool {
@@ -38,7 +39,8 @@ func main() {
 
 	// Print information about each hash type
 	for _, hashType := range hashTypes.HashTypes {
-		fmt.Fprintf(w, "%d\t%s\t%s\n", hashType.ID, hashType.Name, hashType.Category)
+		fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%t\t%d\t%s\n", hashType.ID, hashType.Name, hashType.Category,
+			hashType.SlowHash, hashType.IsSalted, hashType.PasswordLenMax, strings.Join(hashType.KernelTypes, ","))
 	}
 	w.Flush()
 
@@ -62,6 +64,9 @@ func main() {
 	md5Type := hashcat.FindHashTypeByName(hashTypes, "MD5")
 	if md5Type != nil {
 		fmt.Printf("  MD5 hash type ID: %d\n", md5Type.ID)
+		if md5Type.HasExample() {
+			fmt.Printf("  Example: %s (password %q)\n", md5Type.ExampleHash, md5Type.ExamplePass)
+		}
 	} else {
 		fmt.Println("  MD5 hash type not found")
 	}
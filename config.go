@@ -2,6 +2,7 @@ package hashcat
 
 import (
 	"os/exec"
+	"time"
 )
 
 // Config stores the configuration for the hashcat client
@@ -9,7 +10,7 @@ type Config struct {
 	// BinaryPath is the path to the hashcat executable
 	BinaryPath string
 
-	// OutputDir is the directory where hashcat will store output files
+	// OutputDir is the directory where the files and state of restorable sessions are kept
 	OutputDir string
 
 	// DefaultAttackMode is the default attack mode to use
@@ -20,6 +21,14 @@ type Config struct {
 
 	// AdditionalOptions contains additional command-line options to pass to hashcat
 	AdditionalOptions []string
+
+	// PotfilePath is a persistent potfile shared by all sessions. When empty, each
+	// session uses a temporary potfile that is removed with its other files.
+	PotfilePath string
+
+	// StopTimeout is how long Stop waits for hashcat to checkpoint and quit
+	// before terminating it
+	StopTimeout time.Duration
 }
 
 // Option is a function that configures a Config
@@ -38,6 +47,7 @@ func DefaultConfig() *Config {
 		OutputDir:         "./hashcat-output",
 		DefaultAttackMode: 0, // Straight mode
 		DefaultHashType:   0, // MD5
+		StopTimeout:       30 * time.Second,
 	}
 }
 
@@ -96,3 +106,29 @@ func WithAdditionalOptions(options ...string) Option {
 		return nil
 	}
 }
+
+// WithStopTimeout sets how long Stop waits for hashcat to checkpoint and quit
+// before terminating it
+func WithStopTimeout(timeout time.Duration) Option {
+	return func(c *Config) error {
+		if timeout <= 0 {
+			return ErrInvalidStopTimeout
+		}
+
+		c.StopTimeout = timeout
+		return nil
+	}
+}
+
+// WithPotfile points all sessions at a shared, persistent potfile instead of a
+// temporary one per session
+func WithPotfile(path string) Option {
+	return func(c *Config) error {
+		if path == "" {
+			return ErrInvalidPotfilePath
+		}
+
+		c.PotfilePath = path
+		return nil
+	}
+}
@@ -10,8 +10,10 @@ import (
 	"os/exec"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/pixelsquared/go-hashcat/mask"
 	"github.com/pixelsquared/go-hashcat/models"
 )
 
@@ -20,79 +22,402 @@ type CrackSession interface {
 	// Start initiates the cracking process
 	Start() error
 
-	// Stop terminates the cracking process
+	// Stop gracefully stops the cracking process
 	Stop() error
 
+	// Shutdown asks hashcat to checkpoint and quit, terminating it if it has not
+	// exited when ctx is done, and returns the final results
+	Shutdown(ctx context.Context) (*StopResult, error)
+
 	// Progress returns a channel that receives progress updates
 	Progress() <-chan *models.Progress
 
+	// LatestProgress returns the most recent progress update, or nil before the first one
+	LatestProgress() *models.Progress
+
+	// DroppedProgress returns the number of progress updates discarded from the Progress
+	// channel by the delivery policy
+	DroppedProgress() uint64
+
+	// Cracked returns a channel that receives each cracked hash as soon as it is seen.
+	// Cracks are dropped rather than stalling the session when the channel is full.
+	Cracked() <-chan *models.CrackedHash
+
+	// DroppedCracks returns the number of cracks that were dropped from the Cracked channel
+	DroppedCracks() uint64
+
+	// Events returns a channel that receives every event of the session, ending with
+	// EventFinished. It must be called before Start to receive all events.
+	Events() <-chan *Event
+
 	// Wait blocks until the cracking process completes
 	Wait() error
 
 	// Results returns the final cracking results
 	Results() ([]*models.CrackedHash, error)
+
+	// Pause suspends the cracking process until Resume is called
+	Pause() error
+
+	// Resume continues a paused cracking process
+	Resume() error
+
+	// Checkpoint asks hashcat to quit once it reaches the next restore point
+	Checkpoint() error
+
+	// Quit asks hashcat to quit immediately, writing its restore file
+	Quit() error
+
+	// State returns the latest known status of the cracking process
+	State() models.CrackingStatus
 }
 
+// Keys understood by hashcat's interactive prompt
+const (
+	keyStatus     = 's'
+	keyPause      = 'p'
+	keyResume     = 'r'
+	keyCheckpoint = 'c'
+	keyQuit       = 'q'
+)
+
 // HashcatCrackSession implements the CrackSession interface
 type HashcatCrackSession struct {
-	client       *HashcatClient
-	cmd          *exec.Cmd
-	progressChan chan *models.Progress
-	resultsChan  chan *models.CrackedHash
-	ctx          context.Context
-	cancel       context.CancelFunc
-	mutex        sync.Mutex
-	isRunning    bool
-	hashFile     string
-	outputFile   string
-	potFile      string
-	sessionName  string
-	results      []*models.CrackedHash
-	errorChan    chan error
-	finalError   error
-	wg           sync.WaitGroup
+	client        *HashcatClient
+	cmd           *exec.Cmd
+	stdin         io.WriteCloser
+	keyMutex      sync.Mutex // Serializes keypresses, so a blocked write never holds mutex
+	exited        chan struct{}
+	exitStatus    *ExitStatus
+	messages      outputClassifier
+	progressFeed  *progressFeed
+	resultsChan   chan *models.CrackedHash
+	ctx           context.Context
+	cancel        context.CancelFunc
+	mutex         sync.Mutex
+	isRunning     bool
+	restoring     bool
+	options       *CrackOptions
+	hashFile      string
+	ownsHashFile  bool
+	outputFile    string
+	potFile       string
+	sharedPotFile bool
+	restoreFile   string
+	sessionName   string
+	tempFiles     []string
+	status        models.CrackingStatus
+	restorePoint  int64
+	results       []*models.CrackedHash
+	hashIndex     hashIndex
+	dropped       atomic.Uint64
+	events        *eventStream
+	guardrail     *guardrail
+	guardrailErr  error
+	errorChan     chan error
+	finalError    error
+	wg            sync.WaitGroup
 }
 
 // CrackOptions defines parameters for a cracking session
 type CrackOptions struct {
 	HashType        int      // Hash type ID
+	SessionName     string   // Name of the session, used to restore it (generated when empty)
+	Restorable      bool     // Keep the session's files and state in the client's OutputDir so RestoreSession can resume it
+	Attack          Attack   `json:"-"` // Typed attack to run (takes precedence over AttackMode and Mask)
 	AttackMode      int      // Attack mode (0=dict, 1=combi, 3=mask, etc.)
 	Mask            string   // Mask for mask attack or wordlist for dictionary attack
-	Rules           []string // Rules to apply
+	Rules           []string // Rules files to apply
 	OptimizedKernel bool     // Use optimized kernels if available (default: true)
 	Workload        int      // Workload profile (1=low, 2=default, 3=high, 4=nightmare)
-	DeviceIDs       []int    // Specific device IDs to use (empty=all devices)
+
+	// Device options
+	DeviceIDs      []int            // Backend device IDs to use (-d, empty=all devices)
+	DeviceTypes    []DeviceType     // OpenCL device types to use (-D, empty=hashcat default)
+	IgnoreBackends []models.Backend // Backends hashcat must not use (--backend-ignore-*)
+
+	// Hardware monitoring options
+	HwmonTempAbort int              // Abort when a device reaches this temperature in °C (--hwmon-temp-abort, 0=hashcat default)
+	HwmonDisable   bool             // Disable temperature and fan speed reads and the watchdog (--hwmon-disable)
+	Guardrails     *GuardrailPolicy // Pause, resume or abort the session to protect the hardware (nil=disabled)
+
+	// Hash file options
+	Username           bool // Each hash is prefixed with a username (--username)
+	HexSalt            bool // Salts are given in hex (--hex-salt)
+	Separator          byte // Separator between username, hash and salt (-p, 0=':')
+	SkipHashValidation bool // Do not check the format of the hashes before starting
+
+	// Outfile options
+	OutfileFormat OutfileFormat // Fields written for each cracked hash (--outfile-format, empty=DefaultOutfileFormat)
+
+	// OnCracked is called with each cracked hash as soon as it is seen, from the goroutine
+	// reading the outfile. Unlike the Cracked channel no crack is ever skipped, but a slow
+	// callback delays the following ones.
+	OnCracked func(*models.CrackedHash) `json:"-"`
+
+	// Progress options
+	ProgressDelivery ProgressDelivery // How updates are delivered on the Progress channel (default ProgressRing)
+	ProgressBuffer   int              // Capacity of the Progress channel with ProgressRing (0=10)
+
+	// Mask options
+	CustomCharset1 string // User-defined charset ?1 (-1)
+	CustomCharset2 string // User-defined charset ?2 (-2)
+	CustomCharset3 string // User-defined charset ?3 (-3)
+	CustomCharset4 string // User-defined charset ?4 (-4)
+	HexCharset     bool   // Custom charsets and mask literals are given in hex (--hex-charset)
+	Increment      bool   // Enable mask increment mode (-i)
+	IncrementMin   int    // Start mask incrementing at this length (0=hashcat default)
+	IncrementMax   int    // Stop mask incrementing at this length (0=hashcat default)
+
+	// Markov options
+	MarkovDisable   bool   // Disable markov chains, emulates classic brute-force
+	MarkovClassic   bool   // Use classic markov chains, no per-position
+	MarkovInverse   bool   // Use inverse markov chains, no per-position
+	MarkovThreshold int    // Stop accepting new markov chains at this threshold (0=no limit)
+	MarkovHcstat2   string // hcstat2 file to use instead of the built-in one
+}
+
+// attack returns the typed attack for the options, falling back to AttackMode and Mask
+func (o *CrackOptions) attack() (Attack, error) {
+	if o.Attack != nil {
+		return o.Attack, nil
+	}
+	return NewAttack(o.AttackMode, o.Mask)
+}
+
+// customCharsets returns the custom charset definitions indexed by charset number - 1
+func (o *CrackOptions) customCharsets() [4]string {
+	return [4]string{o.CustomCharset1, o.CustomCharset2, o.CustomCharset3, o.CustomCharset4}
+}
+
+// separator returns the separator between username, hash, salt and outfile fields
+func (o *CrackOptions) separator() byte {
+	if o.Separator == 0 {
+		return ':'
+	}
+	return o.Separator
+}
+
+// skipHashValidation reports whether the hashes should be left for hashcat to check.
+// The validators expect the default separator, so hashes using another one are not checked.
+func (o *CrackOptions) skipHashValidation() bool {
+	return o != nil && (o.SkipHashValidation || (o.Separator != 0 && o.Separator != ':'))
+}
+
+// validateHash checks the format of a single hash for the hash type, unless disabled.
+// Nil options validate the hash as MD5, the default hash type.
+func (o *CrackOptions) validateHash(hash string) error {
+	if o == nil {
+		return ValidateHash(0, hash, false)
+	}
+	if o.skipHashValidation() {
+		return nil
+	}
+	return ValidateHash(o.HashType, hash, o.Username)
+}
+
+// validateHashFile checks the format of each hash of a hash file, unless disabled
+func (o *CrackOptions) validateHashFile(path string) error {
+	if o == nil {
+		return ValidateHashFile(path, 0, false)
+	}
+	if o.skipHashValidation() {
+		return nil
+	}
+	return ValidateHashFile(path, o.HashType, o.Username)
+}
+
+// Validate checks the options before a session is started. It validates the attack,
+// parses every mask it uses against the custom charsets and checks the increment bounds.
+func (o *CrackOptions) Validate() error {
+	if o.HashType < 0 {
+		return ErrInvalidHashType
+	}
+
+	if o.SessionName != "" {
+		if err := validateSessionName(o.SessionName); err != nil {
+			return err
+		}
+	}
+
+	attack, err := o.attack()
+	if err != nil {
+		return err
+	}
+
+	if err := attack.Validate(); err != nil {
+		return err
+	}
+
+	if err := o.ProgressDelivery.Validate(); err != nil {
+		return err
+	}
+
+	if o.ProgressBuffer < 0 {
+		return fmt.Errorf("%w: progress buffer must not be negative", ErrInvalidCrackOptions)
+	}
+
+	if err := validateDeviceSelection(o.DeviceIDs, o.DeviceTypes, o.IgnoreBackends); err != nil {
+		return err
+	}
+
+	if o.HwmonTempAbort < 0 {
+		return fmt.Errorf("%w: hwmon abort temperature must not be negative", ErrInvalidCrackOptions)
+	}
+
+	if o.HwmonTempAbort > 0 && o.HwmonDisable {
+		return fmt.Errorf("%w: hwmon abort temperature requires hardware monitoring", ErrInvalidCrackOptions)
+	}
+
+	if o.Guardrails != nil {
+		if err := o.Guardrails.Validate(); err != nil {
+			return err
+		}
+		if o.HwmonDisable && (o.Guardrails.watchesTemperature() || o.Guardrails.StallSamples != 0) {
+			return fmt.Errorf("%w: guardrails require hardware monitoring", ErrInvalidCrackOptions)
+		}
+	}
+
+	if o.MarkovThreshold < 0 {
+		return fmt.Errorf("%w: markov threshold must not be negative", ErrInvalidCrackOptions)
+	}
+
+	if err := o.OutfileFormat.Validate(); err != nil {
+		return err
+	}
+
+	if (o.IncrementMin != 0 || o.IncrementMax != 0) && !o.Increment {
+		return fmt.Errorf("%w: increment bounds require increment mode", ErrInvalidCrackOptions)
+	}
+
+	masked, ok := attack.(maskedAttack)
+	if !ok {
+		if o.Increment {
+			return fmt.Errorf("%w: increment mode requires a mask attack", ErrInvalidCrackOptions)
+		}
+		return nil
+	}
+
+	for _, entry := range masked.maskEntries() {
+		m, err := o.parseMask(entry)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidCrackOptions, err)
+		}
+
+		if err := o.validateIncrement(m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseMask parses a mask entry, falling back to the options' custom charsets for
+// any charset the entry does not define itself
+func (o *CrackOptions) parseMask(entry mask.Entry) (*mask.Mask, error) {
+	cfg := entry.Config(o.HexCharset)
+	for i, charset := range o.customCharsets() {
+		if cfg.CustomCharsets[i] == "" {
+			cfg.CustomCharsets[i] = charset
+		}
+	}
+
+	return mask.ParseWith(entry.Mask, cfg)
+}
+
+// validateIncrement checks that the increment bounds fit within the mask length
+func (o *CrackOptions) validateIncrement(m *mask.Mask) error {
+	if !o.Increment {
+		return nil
+	}
+
+	if o.IncrementMin < 0 || o.IncrementMax < 0 {
+		return fmt.Errorf("%w: increment bounds must not be negative", ErrInvalidCrackOptions)
+	}
+
+	if o.IncrementMin > m.Len() || o.IncrementMax > m.Len() {
+		return fmt.Errorf("%w: increment bounds %d-%d exceed length %d of mask %q",
+			ErrInvalidCrackOptions, o.IncrementMin, o.IncrementMax, m.Len(), m.Raw)
+	}
+
+	if o.IncrementMax != 0 && o.IncrementMin > o.IncrementMax {
+		return fmt.Errorf("%w: increment minimum %d is greater than maximum %d",
+			ErrInvalidCrackOptions, o.IncrementMin, o.IncrementMax)
+	}
+
+	return nil
+}
+
+// maskArgs renders the custom charset, increment and markov options
+func (o *CrackOptions) maskArgs() []string {
+	var args []string
+
+	for i, charset := range o.customCharsets() {
+		if charset != "" {
+			args = append(args, fmt.Sprintf("--custom-charset%d=%s", i+1, charset))
+		}
+	}
+
+	if o.HexCharset {
+		args = append(args, "--hex-charset")
+	}
+
+	if o.Increment {
+		args = append(args, "--increment")
+		if o.IncrementMin > 0 {
+			args = append(args, fmt.Sprintf("--increment-min=%d", o.IncrementMin))
+		}
+		if o.IncrementMax > 0 {
+			args = append(args, fmt.Sprintf("--increment-max=%d", o.IncrementMax))
+		}
+	}
+
+	if o.MarkovDisable {
+		args = append(args, "--markov-disable")
+	}
+	if o.MarkovClassic {
+		args = append(args, "--markov-classic")
+	}
+	if o.MarkovInverse {
+		args = append(args, "--markov-inverse")
+	}
+	if o.MarkovThreshold > 0 {
+		args = append(args, fmt.Sprintf("--markov-threshold=%d", o.MarkovThreshold))
+	}
+	if o.MarkovHcstat2 != "" {
+		args = append(args, fmt.Sprintf("--markov-hcstat2=%s", o.MarkovHcstat2))
+	}
+
+	return args
 }
 
 // NewCrackSession creates a new CrackSession for cracking a single hash
 func (c *HashcatClient) NewCrackSession(ctx context.Context, hash string, options *CrackOptions) (CrackSession, error) {
-	// Create temporary files for this session
-	sessionName := fmt.Sprintf("hashcat-%d", time.Now().UnixNano())
-	hashFile, err := createTempFile(sessionName+"-hash.txt", hash+"\n")
+	if options != nil {
+		if err := options.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := options.validateHash(hash); err != nil {
+		return nil, err
+	}
+
+	sessionName, err := c.newSessionName(options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create hash file: %w", err)
+		return nil, err
 	}
 
-	outputFile := hashFile + ".out"
-	potFile := hashFile + ".pot"
+	// Keep the hash file next to the session state when the session can be restored
+	hashFile, err := createTempFile(c.sessionDir(options), sessionName+"-hash-*.txt", hash+"\n")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create hash file: %w", err)
+	}
 
-	// Create derived context with cancellation and options
-	optionsCtx := context.WithValue(ctx, "options", options)
-	ctx, cancel := context.WithCancel(optionsCtx)
+	session := c.newSession(ctx, sessionName, hashFile, options)
+	session.ownsHashFile = true
 
-	return &HashcatCrackSession{
-		client:       c,
-		progressChan: make(chan *models.Progress, 10),
-		resultsChan:  make(chan *models.CrackedHash, 100),
-		ctx:          ctx,
-		cancel:       cancel,
-		hashFile:     hashFile,
-		outputFile:   outputFile,
-		potFile:      potFile,
-		sessionName:  sessionName,
-		results:      []*models.CrackedHash{},
-		errorChan:    make(chan error, 1),
-	}, nil
+	return session, nil
 }
 
 // NewCrackFileSession creates a new CrackSession for cracking multiple hashes from a file
@@ -102,41 +427,48 @@ func (c *HashcatClient) NewCrackFileSession(ctx context.Context, hashFilePath st
 		return nil, fmt.Errorf("hash file not found: %w", err)
 	}
 
-	sessionName := fmt.Sprintf("hashcat-%d", time.Now().UnixNano())
-	outputFile := hashFilePath + ".out"
-	potFile := hashFilePath + ".pot"
+	if options != nil {
+		if err := options.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := options.validateHashFile(hashFilePath); err != nil {
+		return nil, err
+	}
 
-	// Create derived context with cancellation and options
-	optionsCtx := context.WithValue(ctx, "options", options)
-	ctx, cancel := context.WithCancel(optionsCtx)
+	sessionName, err := c.newSessionName(options)
+	if err != nil {
+		return nil, err
+	}
 
-	return &HashcatCrackSession{
-		client:       c,
-		progressChan: make(chan *models.Progress, 10),
-		resultsChan:  make(chan *models.CrackedHash, 100),
-		ctx:          ctx,
-		cancel:       cancel,
-		hashFile:     hashFilePath,
-		outputFile:   outputFile,
-		potFile:      potFile,
-		sessionName:  sessionName,
-		results:      []*models.CrackedHash{},
-		errorChan:    make(chan error, 1),
-	}, nil
-}
-
-// Start initiates the cracking process
-func (s *HashcatCrackSession) Start() error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	return c.newSession(ctx, sessionName, hashFilePath, options), nil
+}
 
-	if s.isRunning {
-		return fmt.Errorf("cracking session already running")
+// newSessionName returns the session name from the options or generates a unique one,
+// making sure no restorable session with that name already exists. The output directory
+// is only created for restorable sessions.
+func (c *HashcatClient) newSessionName(options *CrackOptions) (string, error) {
+	if options != nil && options.Restorable {
+		if err := os.MkdirAll(c.config.OutputDir, 0700); err != nil {
+			return "", fmt.Errorf("failed to create output directory: %w", err)
+		}
 	}
 
-	// Extract options from context
-	options, ok := s.ctx.Value("options").(*CrackOptions)
-	if !ok {
+	if options == nil || options.SessionName == "" {
+		return fmt.Sprintf("hashcat-%d", time.Now().UnixNano()), nil
+	}
+
+	if _, err := os.Stat(c.sessionStatePath(options.SessionName)); err == nil {
+		return "", fmt.Errorf("%w: %s already exists, use RestoreSession to resume it", ErrSessionExists, options.SessionName)
+	}
+
+	return options.SessionName, nil
+}
+
+// newSession creates a session for the given hash file
+func (c *HashcatClient) newSession(ctx context.Context, sessionName, hashFile string, options *CrackOptions) *HashcatCrackSession {
+	if options == nil {
 		options = &CrackOptions{
 			HashType:        0,
 			AttackMode:      0,
@@ -144,43 +476,84 @@ func (s *HashcatCrackSession) Start() error {
 		}
 	}
 
-	// Construct command arguments
-	args := []string{
-		fmt.Sprintf("--hash-type=%d", options.HashType),
-		fmt.Sprintf("--attack-mode=%d", options.AttackMode),
-		"--quiet",
-		"--status",
-		"--status-json",
-		"--status-timer", "1",
-		"--session", s.sessionName,
-		"--outfile", s.outputFile,
-		"--potfile-path", s.potFile,
-		s.hashFile,
-		"", // Mask or wordlist, will be properly set when using options
+	// Write the outfile in a format that can always be parsed back
+	if len(options.OutfileFormat) == 0 {
+		withFormat := *options
+		withFormat.OutfileFormat = DefaultOutfileFormat
+		options = &withFormat
 	}
 
-	// Add optimized kernel if requested
-	if options.OptimizedKernel {
-		args = append([]string{"--optimized-kernel-enable"}, args...)
+	// Create derived context with cancellation
+	ctx, cancel := context.WithCancel(ctx)
+	hashFile = absPath(hashFile)
+
+	// Sessions share the configured potfile, or get a temporary one of their own
+	potFile := hashFile + ".pot"
+	sharedPotFile := c.config.PotfilePath != ""
+	if sharedPotFile {
+		potFile = absPath(c.config.PotfilePath)
 	}
 
-	// Set workload profile if specified
-	if options.Workload > 0 {
-		args = append(args[:len(args)-1], fmt.Sprintf("--workload-profile=%d", options.Workload))
+	return &HashcatCrackSession{
+		client:        c,
+		progressFeed:  newProgressFeed(options.ProgressDelivery, options.ProgressBuffer),
+		resultsChan:   make(chan *models.CrackedHash, 100),
+		ctx:           ctx,
+		cancel:        cancel,
+		options:       options,
+		hashFile:      hashFile,
+		outputFile:    hashFile + ".out",
+		potFile:       potFile,
+		sharedPotFile: sharedPotFile,
+		restoreFile:   c.restoreFilePath(sessionName, options.Restorable),
+		sessionName:   sessionName,
+		results:       []*models.CrackedHash{},
+		events:        newEventStream(),
+		guardrail:     newGuardrail(options.Guardrails),
+		errorChan:     make(chan error, 1),
 	}
+}
 
-	// Add rules if specified
-	for _, rule := range options.Rules {
-		args = append(args[:len(args)-1], fmt.Sprintf("--rule=%s", rule))
+// Start initiates the cracking process. If hashcat cannot be started, the files created
+// for a new session, including its saved state, are removed.
+func (s *HashcatCrackSession) Start() error {
+	s.mutex.Lock()
+	running := s.isRunning
+	s.mutex.Unlock()
+
+	if running {
+		return fmt.Errorf("cracking session already running")
+	}
+
+	// Fail before hashcat starts if a selected device does not exist. Listing the
+	// devices runs hashcat, so it is done without holding the session lock.
+	if err := s.checkDevices(); err != nil {
+		s.discard()
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isRunning {
+		return fmt.Errorf("cracking session already running")
 	}
 
-	// Add device IDs if specified
-	for _, deviceID := range options.DeviceIDs {
-		args = append(args[:len(args)-1], fmt.Sprintf("--opencl-device-types=%d", deviceID))
+	if err := s.start(); err != nil {
+		s.discard()
+		return err
 	}
 
-	// Replace the last empty arg with the mask/wordlist
-	args[len(args)-1] = options.Mask
+	return nil
+}
+
+// start prepares the session and starts hashcat. The caller must hold the mutex.
+func (s *HashcatCrackSession) start() error {
+	// Construct command arguments
+	args, err := s.commandArgs()
+	if err != nil {
+		return err
+	}
 
 	// Set up and execute command
 	cmd := exec.CommandContext(s.ctx, s.client.config.BinaryPath, args...)
@@ -198,12 +571,22 @@ func (s *HashcatCrackSession) Start() error {
 		return fmt.Errorf("failed to get stderr pipe: %w", err)
 	}
 
+	// Get stdin pipe to drive hashcat's interactive prompt
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to get stdin pipe: %w", err)
+	}
+	s.stdin = stdin
+
 	// Start the command
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start hashcat: %w", err)
 	}
 
 	s.isRunning = true
+	s.exited = make(chan struct{})
+	s.events.start()
+	s.emit(&Event{Type: EventStarted})
 
 	// Process stdout for progress updates
 	s.wg.Add(1)
@@ -213,13 +596,170 @@ func (s *HashcatCrackSession) Start() error {
 	s.wg.Add(1)
 	go s.monitorResults()
 
+	// Report how hashcat exited once everything has been read
+	go s.finish()
+
+	return nil
+}
+
+// commandArgs prepares the session and returns the hashcat arguments to run it.
+// Restored sessions only need their name, as hashcat reads the rest from the restore file.
+func (s *HashcatCrackSession) commandArgs() ([]string, error) {
+	if s.restoring {
+		return []string{
+			"--session", s.sessionName,
+			"--restore",
+			"--restore-file-path", s.restoreFile,
+		}, nil
+	}
+
+	// The options may have been changed since the session was created
+	if err := s.options.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Write any files the attack needs
+	if err := s.prepareAttack(s.options); err != nil {
+		return nil, err
+	}
+
+	args, err := s.buildArgs(s.options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Persist the session so it can be restored if this process goes away
+	if s.options.Restorable {
+		if err := s.saveState(); err != nil {
+			return nil, err
+		}
+	}
+
+	return args, nil
+}
+
+// checkDevices checks the selected backend device IDs against the devices hashcat lists.
+// Restored sessions are not checked, as hashcat reads their devices from the restore file.
+func (s *HashcatCrackSession) checkDevices() error {
+	if s.restoring || len(s.options.DeviceIDs) == 0 {
+		return nil
+	}
+
+	devices, err := s.client.GetDevices(s.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check devices: %w", err)
+	}
+
+	return checkDeviceIDs(devices, s.options.DeviceIDs)
+}
+
+// prepareAttack writes the files needed by attacks such as mask lists
+func (s *HashcatCrackSession) prepareAttack(options *CrackOptions) error {
+	attack, err := options.attack()
+	if err != nil {
+		return err
+	}
+
+	preparer, ok := attack.(attackPreparer)
+	if !ok {
+		return nil
+	}
+
+	tempFile, err := preparer.prepare(s.client.sessionDir(options), s.sessionName)
+	if err != nil {
+		return err
+	}
+
+	if tempFile != "" {
+		s.tempFiles = append(s.tempFiles, tempFile)
+	}
+
 	return nil
 }
 
-// processOutput reads and parses the JSON output from hashcat
+// buildArgs assembles the hashcat command-line arguments for the session
+func (s *HashcatCrackSession) buildArgs(options *CrackOptions) ([]string, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	attack, err := options.attack()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{
+		fmt.Sprintf("--hash-type=%d", options.HashType),
+		fmt.Sprintf("--attack-mode=%d", attack.Mode()),
+		"--quiet",
+		"--status",
+		"--status-json",
+		"--status-timer", "1",
+		"--session", s.sessionName,
+		"--restore-file-path", s.restoreFile,
+		"--outfile", s.outputFile,
+		"--potfile-path", s.potFile,
+	}
+
+	// Describe the layout of the hash file
+	if options.Username {
+		args = append(args, "--username")
+	}
+	if options.HexSalt {
+		args = append(args, "--hex-salt")
+	}
+	if options.Separator != 0 {
+		args = append(args, fmt.Sprintf("--separator=%c", options.Separator))
+	}
+
+	// Choose the fields written for each cracked hash
+	args = append(args, "--outfile-format="+options.OutfileFormat.String())
+
+	// Add optimized kernel if requested
+	if options.OptimizedKernel {
+		args = append(args, "--optimized-kernel-enable")
+	}
+
+	// Set workload profile if specified
+	if options.Workload > 0 {
+		args = append(args, fmt.Sprintf("--workload-profile=%d", options.Workload))
+	}
+
+	// Add rules if specified
+	for _, rule := range options.Rules {
+		args = append(args, fmt.Sprintf("--rules-file=%s", rule))
+	}
+
+	// Select devices, device types and backends
+	args = append(args, deviceArgs(options.DeviceIDs, options.DeviceTypes, options.IgnoreBackends)...)
+
+	// Configure the hardware monitor and its temperature watchdog
+	if options.HwmonDisable {
+		args = append(args, "--hwmon-disable")
+	}
+	if options.HwmonTempAbort > 0 {
+		args = append(args, fmt.Sprintf("--hwmon-temp-abort=%d", options.HwmonTempAbort))
+	}
+
+	// Add custom charsets, increment and markov settings
+	args = append(args, options.maskArgs()...)
+
+	// Add attack specific options
+	args = append(args, attack.Options()...)
+
+	// The hash file is followed by the attack's positional arguments
+	args = append(args, s.hashFile)
+	args = append(args, attack.Arguments()...)
+
+	return args, nil
+}
+
+// processOutput reads and parses the JSON output from hashcat until both output
+// streams are closed, then reaps the process
 func (s *HashcatCrackSession) processOutput(stdout, stderr io.ReadCloser) {
 	defer s.wg.Done()
-	defer close(s.progressChan)
+	defer s.progressFeed.close()
+	defer s.waitForExit()
 
 	// Create scanner for stdout
 	scanner := bufio.NewScanner(stdout)
@@ -228,17 +768,13 @@ func (s *HashcatCrackSession) processOutput(stdout, stderr io.ReadCloser) {
 	errScanner := bufio.NewScanner(stderr)
 
 	// Start a goroutine to collect stderr output
+	var stderrDone sync.WaitGroup
+	stderrDone.Add(1)
 	go func() {
-		var errOutput string
-		for errScanner.Scan() {
-			errOutput += errScanner.Text() + "\n"
-		}
+		defer stderrDone.Done()
 
-		if errOutput != "" {
-			select {
-			case s.errorChan <- fmt.Errorf("hashcat error: %s", errOutput):
-			default:
-			}
+		for errScanner.Scan() {
+			s.warn(errScanner.Text())
 		}
 	}()
 
@@ -249,152 +785,368 @@ func (s *HashcatCrackSession) processOutput(stdout, stderr io.ReadCloser) {
 		// Parse JSON progress update
 		var progress models.Progress
 		if err := json.Unmarshal([]byte(line), &progress); err == nil {
+			s.mutex.Lock()
+			s.status = progress.Status
+			s.restorePoint = progress.RestorePoint
+			s.mutex.Unlock()
+
+			s.emit(&Event{Type: EventProgress, Progress: &progress})
+
+			// Check the guardrails first, as a lossless progress channel may block until
+			// the reader catches up
+			s.enforceGuardrails(&progress)
+
 			// Send progress update through channel
-			select {
-			case s.progressChan <- &progress:
-			default:
-				// Channel buffer is full, discard oldest value and try again
-				<-s.progressChan
-				s.progressChan <- &progress
-			}
-
-			// If status indicates completion, break
-			if progress.Status == models.StatusCracked ||
-				progress.Status == models.StatusExhausted ||
-				progress.Status == models.StatusAborted {
-				break
-			}
+			s.progressFeed.publish(&progress, s.ctx.Done())
+			continue
 		}
+
+		// Anything else hashcat prints is a notice or warning
+		s.warn(line)
 	}
 
 	// Check for errors
 	if err := scanner.Err(); err != nil {
-		select {
-		case s.errorChan <- fmt.Errorf("error reading output: %w", err):
-		default:
-		}
+		s.reportError(fmt.Errorf("error reading output: %w", err))
 	}
+
+	// All reads must be complete before the process can be reaped
+	stderrDone.Wait()
 }
 
-// monitorResults monitors the output file for cracked hashes
+// waitForExit reaps the hashcat process, classifies how it exited and signals that it
+// has exited
+func (s *HashcatCrackSession) waitForExit() {
+	err := s.cmd.Wait()
+	code, signaled := exitCode(err)
+
+	s.mutex.Lock()
+	s.exitStatus = &ExitStatus{
+		Code:     code,
+		Signaled: signaled,
+		Status:   s.status,
+		Err:      classifyExit("crack", err, &s.messages),
+	}
+
+	// Report why a guardrail aborted the session rather than how hashcat quit
+	if s.guardrailErr != nil {
+		s.exitStatus.Err = &HashcatError{Operation: "crack", ExitCode: code, Err: s.guardrailErr}
+	}
+	s.isRunning = false
+	s.mutex.Unlock()
+
+	close(s.exited)
+}
+
+// monitorResults follows the output file for cracked hashes. The file is read until
+// hashcat has exited, including after the session is canceled, so that no crack written
+// before the process ended is missed.
 func (s *HashcatCrackSession) monitorResults() {
 	defer s.wg.Done()
 	defer close(s.resultsChan)
 
-	// Check output file for results periodically
-	ticker := time.NewTicker(500 * time.Millisecond)
-	defer ticker.Stop()
-
-	var lastSize int64
-
-	for {
-		select {
-		case <-ticker.C:
-			// Get file info
-			info, err := os.Stat(s.outputFile)
-			if err != nil {
-				if !os.IsNotExist(err) {
-					// Only report error if it's not just that the file doesn't exist yet
-					select {
-					case s.errorChan <- fmt.Errorf("error checking output file: %w", err):
-					default:
-					}
-				}
-				continue
-			}
-
-			// If file size hasn't changed, skip
-			if info.Size() <= lastSize {
-				continue
-			}
-
-			// Open file and seek to last read position
-			file, err := os.Open(s.outputFile)
-			if err != nil {
-				select {
-				case s.errorChan <- fmt.Errorf("error opening output file: %w", err):
-				default:
-				}
-				continue
-			}
-
-			if _, err := file.Seek(lastSize, 0); err != nil {
-				file.Close()
-				select {
-				case s.errorChan <- fmt.Errorf("error seeking in output file: %w", err):
-				default:
-				}
-				continue
-			}
-
-			// Read new content
-			scanner := bufio.NewScanner(file)
-			for scanner.Scan() {
-				line := scanner.Text()
-
-				// Parse cracked hash
-				parts := splitHashResult(line)
-				if len(parts) >= 2 {
-					hash := parts[0]
-					password := parts[1]
-
-					result := &models.CrackedHash{
-						Hash:     hash,
-						Password: password,
-						Time:     time.Now().Unix(),
-					}
-
-					// Send through channel and add to results slice
-					s.resultsChan <- result
-
-					s.mutex.Lock()
-					s.results = append(s.results, result)
-					s.mutex.Unlock()
-				}
-			}
-
-			lastSize = info.Size()
-			file.Close()
-
-		case <-s.ctx.Done():
-			return
-		}
+	// Index the hash file to trace cracked hashes back to their lines and accounts
+	index, err := buildHashIndex(s.hashFile, s.options.Username, s.options.separator())
+	if err != nil {
+		s.reportError(fmt.Errorf("error indexing hash file: %w", err))
+	}
+	s.mutex.Lock()
+	s.hashIndex = index
+	s.mutex.Unlock()
+
+	// Canceling the session kills hashcat, so exited is closed in either case
+	tailer := newOutfileTailer(s.outputFile)
+	if err := tailer.Follow(s.exited, s.handleResult); err != nil {
+		s.reportError(err)
+	}
+}
+
+// handleResult records a line of the output file and notifies consumers of the crack
+func (s *HashcatCrackSession) handleResult(line string) {
+	// Parse cracked hash
+	result := s.parseResult(line)
+	if result == nil {
+		return
+	}
+
+	// Add to results slice before anyone is notified
+	s.mutex.Lock()
+	s.results = append(s.results, result)
+	s.mutex.Unlock()
+
+	if s.options.OnCracked != nil {
+		s.options.OnCracked(result)
+	}
+
+	s.emit(&Event{Type: EventCracked, Cracked: result})
+
+	// Never block on a slow reader; the result is still in Results
+	select {
+	case s.resultsChan <- result:
+	default:
+		s.dropped.Add(1)
 	}
 }
 
-// Progress returns the progress channel
+// parseResult parses a line of the output file and links it to the hash file lines it
+// came from. It returns nil for lines that cannot be parsed.
+func (s *HashcatCrackSession) parseResult(line string) *models.CrackedHash {
+	result, err := ParseOutfileLine(line, s.options.OutfileFormat, s.options.separator())
+	if err != nil {
+		return nil
+	}
+
+	result.Time = time.Now().Unix()
+
+	s.mutex.Lock()
+	index := s.hashIndex
+	s.mutex.Unlock()
+
+	index.resolve(result, s.options.separator())
+
+	return result
+}
+
+// Cracked returns the channel that receives each cracked hash. It is buffered and closed
+// once the session has ended and the outfile has been drained.
+func (s *HashcatCrackSession) Cracked() <-chan *models.CrackedHash {
+	return s.resultsChan
+}
+
+// DroppedCracks returns the number of cracks that were dropped from the Cracked channel
+// because it was full. Dropped cracks are still returned by Results.
+func (s *HashcatCrackSession) DroppedCracks() uint64 {
+	return s.dropped.Load()
+}
+
+// Events returns the channel that receives every event of the session. Events are only
+// recorded once Events has been called, so call it before Start to receive them all.
+// Events are queued until they are read and never dropped; the channel must be read
+// until it is closed after EventFinished.
+func (s *HashcatCrackSession) Events() <-chan *Event {
+	return s.events.open()
+}
+
+// emit adds an event for the session to its event stream
+func (s *HashcatCrackSession) emit(event *Event) {
+	event.Session = s.sessionName
+	s.events.emit(event)
+}
+
+// warn reports a line printed by hashcat that is not a status update, keeping it to
+// explain a failure
+func (s *HashcatCrackSession) warn(line string) {
+	if line = strings.TrimSpace(line); line != "" {
+		s.messages.add(line)
+		s.emit(&Event{Type: EventWarning, Message: line})
+	}
+}
+
+// reportError emits an error event and keeps the first error for Wait to return
+func (s *HashcatCrackSession) reportError(err error) {
+	s.emit(&Event{Type: EventError, Err: err})
+
+	select {
+	case s.errorChan <- err:
+	default:
+	}
+}
+
+// finish emits EventFinished and closes the event stream once hashcat has exited and
+// all of its output has been read
+func (s *HashcatCrackSession) finish() {
+	s.wg.Wait()
+
+	s.mutex.Lock()
+	exit := *s.exitStatus
+	exit.Cracked = len(s.results)
+	s.mutex.Unlock()
+	exit.Restorable = s.Restorable()
+
+	// Running out of candidates or being stopped is not a failure
+	if exit.Err != nil && !IsStopOutcome(exit.Err) {
+		s.emit(&Event{Type: EventError, Err: exit.Err})
+	}
+
+	s.emit(&Event{Type: EventFinished, Exit: &exit})
+	s.events.close()
+}
+
+// Progress returns the progress channel. How updates are delivered on it depends on
+// the ProgressDelivery option; it is closed once hashcat's output has been read.
 func (s *HashcatCrackSession) Progress() <-chan *models.Progress {
-	return s.progressChan
+	return s.progressFeed.ch
+}
+
+// LatestProgress returns the most recent progress update, whatever the delivery policy,
+// or nil before the first one
+func (s *HashcatCrackSession) LatestProgress() *models.Progress {
+	return s.progressFeed.latest.Load()
+}
+
+// DroppedProgress returns the number of progress updates discarded from the Progress
+// channel because the reader fell behind. It is always zero with ProgressLossless.
+func (s *HashcatCrackSession) DroppedProgress() uint64 {
+	return s.progressFeed.dropped.Load()
 }
 
-// Stop terminates the cracking process
+// Stop gracefully stops the cracking process, waiting at most the client's
+// configured stop timeout before terminating it
 func (s *HashcatCrackSession) Stop() error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	defer s.cleanup() // Clean up temporary files
+	ctx, cancel := context.WithTimeout(context.Background(), s.client.config.StopTimeout)
+	defer cancel()
+
+	_, err := s.Shutdown(ctx)
+	return err
+}
+
+// Pause suspends the cracking process until Resume is called
+func (s *HashcatCrackSession) Pause() error {
+	s.keyMutex.Lock()
+	defer s.keyMutex.Unlock()
+
+	if s.State() == models.StatusPaused {
+		return nil
+	}
+
+	if err := s.sendKey(keyPause); err != nil {
+		return err
+	}
+
+	s.setStatus(models.StatusPaused)
+	s.emit(&Event{Type: EventPaused})
+	return nil
+}
+
+// Resume continues a paused cracking process
+func (s *HashcatCrackSession) Resume() error {
+	s.keyMutex.Lock()
+	defer s.keyMutex.Unlock()
 
-	if !s.isRunning {
+	if s.State() != models.StatusPaused {
 		return nil
 	}
 
-	// Cancel context to stop all operations
-	s.cancel()
+	if err := s.sendKey(keyResume); err != nil {
+		return err
+	}
+
+	s.setStatus(models.StatusRunning)
+	s.emit(&Event{Type: EventResumed})
+	return nil
+}
 
-	// Kill the process if it's still running
-	if s.cmd != nil && s.cmd.Process != nil {
-		return s.cmd.Process.Kill()
+// Checkpoint asks hashcat to quit once it reaches the next restore point,
+// so the session can be restored without repeating any work
+func (s *HashcatCrackSession) Checkpoint() error {
+	s.keyMutex.Lock()
+	defer s.keyMutex.Unlock()
+
+	if err := s.sendKey(keyCheckpoint); err != nil {
+		return err
 	}
 
+	s.setStatus(models.StatusRunningCheckpointQuit)
 	return nil
 }
 
-// Wait blocks until the cracking process completes
+// Quit asks hashcat to quit immediately, writing its restore file
+func (s *HashcatCrackSession) Quit() error {
+	s.keyMutex.Lock()
+	defer s.keyMutex.Unlock()
+
+	return s.sendKey(keyQuit)
+}
+
+// RequestStatus asks hashcat to report its status right away instead of
+// waiting for the next status timer
+func (s *HashcatCrackSession) RequestStatus() error {
+	s.keyMutex.Lock()
+	defer s.keyMutex.Unlock()
+
+	return s.sendKey(keyStatus)
+}
+
+// State returns the latest known status of the cracking process. It is updated from
+// every progress update and immediately when the session is paused or resumed.
+func (s *HashcatCrackSession) State() models.CrackingStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.status
+}
+
+// setStatus records the status of the session
+func (s *HashcatCrackSession) setStatus(status models.CrackingStatus) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.status = status
+}
+
+// sendKey writes a keypress to hashcat's interactive prompt. The caller must hold
+// keyMutex but not the session mutex, so that a write blocked by a wedged hashcat
+// does not block State, Results or the guardrails.
+func (s *HashcatCrackSession) sendKey(key byte) error {
+	s.mutex.Lock()
+	running, stdin := s.isRunning, s.stdin
+	s.mutex.Unlock()
+
+	if !running || stdin == nil {
+		return ErrSessionNotRunning
+	}
+
+	if _, err := stdin.Write([]byte{key}); err != nil {
+		return fmt.Errorf("failed to send %q to hashcat: %w", key, err)
+	}
+
+	return nil
+}
+
+// sendKeyContext sends a keypress like sendKey, but stops waiting when ctx is done. The
+// write is then left to complete, or to fail once hashcat has exited.
+func (s *HashcatCrackSession) sendKeyContext(ctx context.Context, key byte) error {
+	done := make(chan error, 1)
+	go func() {
+		s.keyMutex.Lock()
+		defer s.keyMutex.Unlock()
+
+		done <- s.sendKey(key)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Wait blocks until the cracking process completes. Unless hashcat cracked every hash,
+// it returns a *HashcatError telling how hashcat exited, which wraps ErrExhausted when
+// the keyspace ran out and one of the other exit sentinels otherwise.
 func (s *HashcatCrackSession) Wait() error {
 	// Wait for all goroutines to finish
 	s.wg.Wait()
-	defer s.cleanup() // Clean up temporary files
 
-	// Check for errors
+	// Clean up temporary files, unless hashcat left a restore file behind because
+	// the session did not run to completion
+	if !s.Restorable() {
+		defer s.cleanup()
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// How hashcat exited takes precedence over errors reading its output. Stopping
+	// without a failure is not kept for Results.
+	if s.exitStatus != nil && s.exitStatus.Err != nil {
+		if !IsStopOutcome(s.exitStatus.Err) {
+			s.finalError = s.exitStatus.Err
+		}
+		return s.exitStatus.Err
+	}
+
 	select {
 	case err := <-s.errorChan:
 		s.finalError = err
@@ -405,7 +1157,8 @@ func (s *HashcatCrackSession) Wait() error {
 	return s.finalError
 }
 
-// Results returns the cracked hashes
+// Results returns the cracked hashes. The error is only set when the session failed;
+// exhausting the keyspace or being stopped, see IsStopOutcome, is not reported.
 func (s *HashcatCrackSession) Results() ([]*models.CrackedHash, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -413,58 +1166,103 @@ func (s *HashcatCrackSession) Results() ([]*models.CrackedHash, error) {
 	return s.results, s.finalError
 }
 
+// SessionName returns the hashcat session name, which RestoreSession uses to resume it
+func (s *HashcatCrackSession) SessionName() string {
+	return s.sessionName
+}
+
+// RestoreFilePath returns the path of the hashcat restore file for the session
+func (s *HashcatCrackSession) RestoreFilePath() string {
+	return s.restoreFile
+}
+
+// Options returns the options the session was created with
+func (s *HashcatCrackSession) Options() *CrackOptions {
+	return s.options
+}
+
+// RestorePoint returns the restore point of the latest progress update
+func (s *HashcatCrackSession) RestorePoint() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.restorePoint
+}
+
+// Restorable reports whether the session was created with the Restorable option and
+// hashcat has written a restore file it can be resumed from
+func (s *HashcatCrackSession) Restorable() bool {
+	if !s.options.Restorable {
+		return false
+	}
+
+	_, err := os.Stat(s.restoreFile)
+	return err == nil
+}
+
+// discard removes the files of a new session that failed to start, so that no state is
+// left behind for a session that never ran. Restored sessions keep their files.
+func (s *HashcatCrackSession) discard() {
+	if !s.restoring {
+		s.cleanup()
+	}
+}
+
 // cleanup removes temporary files created for the session
 func (s *HashcatCrackSession) cleanup() {
-	// Only remove temp hash file if we created it
-	// Don't try to clean up if we haven't set a hash file yet
-	if s.hashFile == "" || strings.HasPrefix(s.hashFile, os.TempDir()) {
+	// Only remove the hash file if we created it
+	if s.ownsHashFile {
 		os.Remove(s.hashFile)
 	}
 
-	// Remove output and pot files
+	// Remove the output file, and the pot file unless it is shared between sessions
 	os.Remove(s.outputFile)
-	os.Remove(s.potFile)
+	if !s.sharedPotFile {
+		os.Remove(s.potFile)
+	}
+
+	// Remove files written for the attack
+	for _, tempFile := range s.tempFiles {
+		os.Remove(tempFile)
+	}
+
+	// Remove the restore file and saved state
+	os.Remove(s.restoreFile)
+	if s.options.Restorable {
+		os.Remove(s.client.sessionStatePath(s.sessionName))
+	}
 }
 
-// Helper function to create a temporary file with content
-func createTempFile(name, content string) (string, error) {
-	file, err := os.CreateTemp("", name)
+// writeNewFile writes content to a file that must not exist yet
+func writeNewFile(path, content string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	defer file.Close()
-
 	if _, err := file.WriteString(content); err != nil {
-		os.Remove(file.Name())
-		return "", err
+		file.Close()
+		os.Remove(path)
+		return err
 	}
 
-	return file.Name(), nil
+	return file.Close()
 }
 
-// Helper function to split hash:password results
-func splitHashResult(line string) []string {
-	var parts []string
-	var current string
-	inEscape := false
-
-	for _, c := range line {
-		if c == '\\' && !inEscape {
-			inEscape = true
-			continue
-		}
+// Helper function to create a temporary file with content in dir
+// (the system temporary directory when dir is empty)
+func createTempFile(dir, name, content string) (string, error) {
+	file, err := os.CreateTemp(dir, name)
+	if err != nil {
+		return "", err
+	}
 
-		if c == ':' && !inEscape {
-			parts = append(parts, current)
-			current = ""
-			continue
-		}
+	defer file.Close()
 
-		current += string(c)
-		inEscape = false
+	if _, err := file.WriteString(content); err != nil {
+		os.Remove(file.Name())
+		return "", err
 	}
 
-	parts = append(parts, current)
-	return parts
+	return file.Name(), nil
 }
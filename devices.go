@@ -9,123 +9,125 @@ import (
 	"github.com/pixelsquared/go-hashcat/models"
 )
 
-// ParseDeviceOutput parses the output from hashcat --backend-info command
+// Headers of the backend information printed by hashcat -I and -II
+var (
+	backendSectionRe = regexp.MustCompile(`^\s*(CUDA|HIP|Metal|OpenCL) Info:\s*$`)
+	platformHeaderRe = regexp.MustCompile(`^\s*OpenCL Platform ID #(\d+)`)
+	deviceHeaderRe   = regexp.MustCompile(`^\s*Backend Device ID #(\d+)\s*(?:\((.*)\))?\s*$`)
+	propertyRe       = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z0-9_()#/-]*(?:\.[A-Za-z0-9_()#/-]+)*)\.*:\s*(.*?)\s*$`)
+	leadingIntRe     = regexp.MustCompile(`^\s*(\d+)`)
+)
+
+// platformFields maps the properties hashcat prints for a platform or backend to the
+// fields they fill
+var platformFields = map[string]func(*models.Platform, string){
+	"Vendor":        func(p *models.Platform, v string) { p.Vendor = v },
+	"Name":          func(p *models.Platform, v string) { p.Name = v },
+	"Version":       func(p *models.Platform, v string) { p.Version = v },
+	"CUDA.Version":  func(p *models.Platform, v string) { p.Version = v },
+	"HIP.Version":   func(p *models.Platform, v string) { p.Version = v },
+	"Metal.Version": func(p *models.Platform, v string) { p.Version = v },
+}
+
+// deviceFields maps the properties hashcat prints for a device to the fields they fill.
+// Properties missing from the table are only kept in Properties.
+var deviceFields = map[string]func(*models.Device, string){
+	"Type":               func(d *models.Device, v string) { d.Type = v },
+	"Vendor.ID":          func(d *models.Device, v string) { d.VendorID = leadingInt(v) },
+	"Vendor":             func(d *models.Device, v string) { d.Vendor = v },
+	"Name":               func(d *models.Device, v string) { d.Name = v },
+	"Version":            func(d *models.Device, v string) { d.Version = v },
+	"Processor(s)":       func(d *models.Device, v string) { d.Processors = leadingInt(v) },
+	"Clock":              func(d *models.Device, v string) { d.ClockMHz = leadingInt(v) },
+	"Memory.Total":       func(d *models.Device, v string) { d.MemoryTotal = leadingInt(v) },
+	"Memory.Free":        func(d *models.Device, v string) { d.MemoryFree = leadingInt(v) },
+	"Local.Memory":       func(d *models.Device, v string) { d.LocalMemory = leadingInt(v) },
+	"OpenCL.Version":     func(d *models.Device, v string) { d.OpenCLVersion = v },
+	"Driver.Version":     func(d *models.Device, v string) { d.DriverVersion = v },
+	"PCI.Addr.BDF":       func(d *models.Device, v string) { d.PCIAddress = v },
+	"PCI.Addr.BDFe":      func(d *models.Device, v string) { d.PCIAddress = v },
+	"Compute.Capability": func(d *models.Device, v string) { d.ComputeCapability = v },
+	"Capability":         func(d *models.Device, v string) { d.ComputeCapability = v },
+	"Skipped":            func(d *models.Device, v string) { d.Skipped = isYes(v) },
+}
+
+// ParseDeviceOutput parses the output of hashcat --backend-info (-I or -II). Devices
+// listed under the CUDA, HIP and Metal backends are grouped into a platform for each
+// backend; OpenCL devices keep their platforms.
 func ParseDeviceOutput(output string) (*models.DeviceList, error) {
-	lines := strings.Split(output, "\n")
 	var platforms []models.Platform
-	var currentPlatform *models.Platform
-	var currentDevice *models.Device
-
-	// Regular expressions for parsing
-	rePlatform := regexp.MustCompile(`(?:^|\n)OpenCL Platform ID #(\d+)`)
-	reDevice := regexp.MustCompile(`(?:^|\n)\s*Backend Device ID #(\d+)`)
-
-	// Property patterns
-	platformVendorRe := regexp.MustCompile(`^\s+Vendor\.\.: (.+)$`)
-	platformNameRe := regexp.MustCompile(`^\s+Name\....: (.+)$`)
-	platformVersionRe := regexp.MustCompile(`^\s+Version\.: (.+)$`)
-
-	// Device properties
-	deviceTypeRe := regexp.MustCompile(`^\s+Type\.\.\.\.\.\.\.\.\.\.\.: (.+)$`)
-	deviceVendorIDRe := regexp.MustCompile(`^\s+Vendor\.ID\.\.\.\.\.\.: (\d+)$`)
-	deviceVendorRe := regexp.MustCompile(`^\s+Vendor\.\.\.\.\.\.\.\.\.: (.+)$`)
-	deviceNameRe := regexp.MustCompile(`^\s+Name\.\.\.\.\.\.\.\.\.\.\.: (.+)$`)
-	deviceVersionRe := regexp.MustCompile(`^\s+Version\.\.\.\.\.\.\.\.: (.+)$`)
-	deviceProcessorsRe := regexp.MustCompile(`^\s+Processor\(s\)\.\.\.: (\d+)$`)
-	deviceClockRe := regexp.MustCompile(`^\s+Clock\.\.\.\.\.\.\.\.\.: (\d+)$`)
-	deviceMemTotalRe := regexp.MustCompile(`^\s+Memory\.Total\.\.\.: (\d+)`)
-	deviceMemFreeRe := regexp.MustCompile(`^\s+Memory\.Free\.\.\.\.: (\d+)`)
-	deviceLocalMemRe := regexp.MustCompile(`^\s+Local\.Memory\.\.\.: (\d+)`)
-	deviceOpenCLVersionRe := regexp.MustCompile(`^\s+OpenCL\.Version\.: (.+)$`)
-	deviceDriverVersionRe := regexp.MustCompile(`^\s+Driver\.Version\.: (.+)$`)
-
-	for i := 0; i < len(lines); i++ {
-		line := lines[i]
-
-		// Check for platform
-		platformMatch := rePlatform.FindStringSubmatch(line)
-		if len(platformMatch) > 1 {
-			// Found a platform, create new platform
-			platformID, _ := strconv.Atoi(platformMatch[1])
-			currentPlatform = &models.Platform{
-				ID:      platformID,
-				Devices: []models.Device{},
-			}
-			currentDevice = nil // Reset current device when changing platform
-			platforms = append(platforms, *currentPlatform)
+	var device *models.Device
+	backend := models.BackendOpenCL
+	inPlatform := false
+
+	// currentPlatform returns the platform new devices and properties belong to,
+	// grouping the devices of non-OpenCL backends into one platform
+	currentPlatform := func() *models.Platform {
+		if !inPlatform {
+			platforms = append(platforms, models.Platform{Backend: backend, Name: string(backend)})
+			inPlatform = true
+		}
+		return &platforms[len(platforms)-1]
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if match := backendSectionRe.FindStringSubmatch(line); match != nil {
+			backend = models.Backend(match[1])
+			inPlatform = false
+			device = nil
 			continue
 		}
 
-		// Check for device
-		deviceMatch := reDevice.FindStringSubmatch(line)
-		if len(deviceMatch) > 1 && currentPlatform != nil {
-			// Found a device, create new device in the latest platform
-			deviceID, _ := strconv.Atoi(deviceMatch[1])
-			device := models.Device{
-				ID: deviceID,
-			}
-			platformIdx := len(platforms) - 1
-			platforms[platformIdx].Devices = append(platforms[platformIdx].Devices, device)
-			// Get a reference to the newly added device
-			currentDevice = &platforms[platformIdx].Devices[len(platforms[platformIdx].Devices)-1]
+		if match := platformHeaderRe.FindStringSubmatch(line); match != nil {
+			id, _ := strconv.Atoi(match[1])
+			backend = models.BackendOpenCL
+			platforms = append(platforms, models.Platform{ID: id, Backend: backend, Devices: []models.Device{}})
+			inPlatform = true
+			device = nil
+			continue
+		}
+
+		if match := deviceHeaderRe.FindStringSubmatch(line); match != nil {
+			platform := currentPlatform()
+			platform.Devices = append(platform.Devices, parseDeviceHeader(match, backend))
+			device = &platform.Devices[len(platform.Devices)-1]
 			continue
 		}
 
-		// Check for platform properties
-		if currentPlatform != nil && currentDevice == nil {
-			if match := platformVendorRe.FindStringSubmatch(line); len(match) > 1 {
-				platforms[len(platforms)-1].Vendor = match[1]
-			} else if match := platformNameRe.FindStringSubmatch(line); len(match) > 1 {
-				platforms[len(platforms)-1].Name = match[1]
-			} else if match := platformVersionRe.FindStringSubmatch(line); len(match) > 1 {
-				platforms[len(platforms)-1].Version = match[1]
+		match := propertyRe.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		name, value := match[1], match[2]
+
+		if device != nil {
+			setProperty(&device.Properties, name, value)
+			if set, ok := deviceFields[name]; ok {
+				set(device, value)
 			}
+			continue
 		}
 
-		// Check for device properties
-		if currentDevice != nil && currentPlatform != nil {
-			platformIdx := len(platforms) - 1
-			deviceIdx := len(platforms[platformIdx].Devices) - 1
-
-			// Make sure we have valid indices before proceeding
-			if platformIdx >= 0 && deviceIdx >= 0 {
-				if match := deviceTypeRe.FindStringSubmatch(line); len(match) > 1 {
-					platforms[platformIdx].Devices[deviceIdx].Type = match[1]
-				} else if match := deviceVendorIDRe.FindStringSubmatch(line); len(match) > 1 {
-					vendorID, _ := strconv.Atoi(match[1])
-					platforms[platformIdx].Devices[deviceIdx].VendorID = vendorID
-				} else if match := deviceVendorRe.FindStringSubmatch(line); len(match) > 1 {
-					platforms[platformIdx].Devices[deviceIdx].Vendor = match[1]
-				} else if match := deviceNameRe.FindStringSubmatch(line); len(match) > 1 {
-					platforms[platformIdx].Devices[deviceIdx].Name = match[1]
-				} else if match := deviceVersionRe.FindStringSubmatch(line); len(match) > 1 {
-					platforms[platformIdx].Devices[deviceIdx].Version = match[1]
-				} else if match := deviceProcessorsRe.FindStringSubmatch(line); len(match) > 1 {
-					processors, _ := strconv.Atoi(match[1])
-					platforms[platformIdx].Devices[deviceIdx].Processors = processors
-				} else if match := deviceClockRe.FindStringSubmatch(line); len(match) > 1 {
-					clock, _ := strconv.Atoi(match[1])
-					platforms[platformIdx].Devices[deviceIdx].ClockMHz = clock
-				} else if match := deviceMemTotalRe.FindStringSubmatch(line); len(match) > 1 {
-					memTotal, _ := strconv.Atoi(match[1])
-					platforms[platformIdx].Devices[deviceIdx].MemoryTotal = memTotal
-				} else if match := deviceMemFreeRe.FindStringSubmatch(line); len(match) > 1 {
-					memFree, _ := strconv.Atoi(match[1])
-					platforms[platformIdx].Devices[deviceIdx].MemoryFree = memFree
-				} else if match := deviceLocalMemRe.FindStringSubmatch(line); len(match) > 1 {
-					localMem, _ := strconv.Atoi(match[1])
-					platforms[platformIdx].Devices[deviceIdx].LocalMemory = localMem
-				} else if match := deviceOpenCLVersionRe.FindStringSubmatch(line); len(match) > 1 {
-					platforms[platformIdx].Devices[deviceIdx].OpenCLVersion = match[1]
-				} else if match := deviceDriverVersionRe.FindStringSubmatch(line); len(match) > 1 {
-					platforms[platformIdx].Devices[deviceIdx].DriverVersion = match[1]
-				}
+		// Properties before the first device describe the platform or backend
+		if backend != models.BackendOpenCL || inPlatform {
+			platform := currentPlatform()
+			setProperty(&platform.Properties, name, value)
+			if set, ok := platformFields[name]; ok {
+				set(platform, value)
 			}
 		}
 	}
 
 	if len(platforms) == 0 {
-		return nil, fmt.Errorf("no OpenCL platforms found in output")
+		return nil, fmt.Errorf("%w: no backend platforms found in output", ErrNoDevices)
+	}
+
+	for i := range platforms {
+		for j := range platforms[i].Devices {
+			normalizeDevice(&platforms[i].Devices[j])
+		}
 	}
 
 	return &models.DeviceList{
@@ -133,6 +135,73 @@ func ParseDeviceOutput(output string) (*models.DeviceList, error) {
 	}, nil
 }
 
+// parseDeviceHeader creates a device from a Backend Device ID header, which may list
+// aliases and a status in parentheses: "Backend Device ID #3 (Alias: #1, skipped)"
+func parseDeviceHeader(match []string, backend models.Backend) models.Device {
+	id, _ := strconv.Atoi(match[1])
+	device := models.Device{ID: id, Backend: backend}
+
+	for _, part := range strings.Split(match[2], ",") {
+		part = strings.TrimSpace(part)
+		lower := strings.ToLower(part)
+
+		switch {
+		case part == "":
+		case strings.HasPrefix(lower, "alias"):
+			_, aliases, _ := strings.Cut(part, ":")
+			for _, alias := range strings.Fields(aliases) {
+				if n, err := strconv.Atoi(strings.TrimPrefix(alias, "#")); err == nil {
+					device.Aliases = append(device.Aliases, n)
+				}
+			}
+		case strings.HasPrefix(part, "#"):
+			// Further aliases of an alias list split at the comma
+			if n, err := strconv.Atoi(strings.TrimPrefix(part, "#")); err == nil {
+				device.Aliases = append(device.Aliases, n)
+			}
+		case strings.Contains(lower, "skipped"), strings.Contains(lower, "ignored"):
+			device.Skipped = true
+		}
+	}
+
+	return device
+}
+
+// normalizeDevice fills in what hashcat leaves implicit: CUDA and HIP only list GPUs
+func normalizeDevice(device *models.Device) {
+	if device.Type == "" && (device.Backend == models.BackendCUDA || device.Backend == models.BackendHIP) {
+		device.Type = "GPU"
+	}
+}
+
+// setProperty records a property as printed, creating the map on first use
+func setProperty(properties *map[string]string, name, value string) {
+	if *properties == nil {
+		*properties = make(map[string]string)
+	}
+	(*properties)[name] = value
+}
+
+// leadingInt returns the number a value starts with, ignoring units and remarks
+// such as "4096 MB (limited to 1024 MB allocatable in one block)"
+func leadingInt(value string) int {
+	match := leadingIntRe.FindStringSubmatch(value)
+	if match == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(match[1])
+	return n
+}
+
+// isYes reports whether a property value is affirmative
+func isYes(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "true", "1", "skipped", "ignored":
+		return true
+	}
+	return false
+}
+
 // FindDeviceByID finds a device with the given ID across all platforms
 func FindDeviceByID(devices *models.DeviceList, id int) *models.Device {
 	for _, platform := range devices.Platforms {
@@ -0,0 +1,175 @@
+package hashcat
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pixelsquared/go-hashcat/models"
+)
+
+// wantPlatform describes a platform expected from ParseDeviceOutput
+type wantPlatform struct {
+	id      int
+	backend models.Backend
+	name    string
+	version string
+	devices []int
+}
+
+// wantDevice describes a device expected from ParseDeviceOutput
+type wantDevice struct {
+	id          int
+	backend     models.Backend
+	deviceType  string
+	name        string
+	memoryTotal int
+	pciAddress  string
+	aliases     []int
+	skipped     bool
+}
+
+func TestParseDeviceOutput(t *testing.T) {
+	tests := []struct {
+		file      string
+		platforms []wantPlatform
+		devices   []wantDevice
+	}{
+		{
+			file: "devices_opencl.txt",
+			platforms: []wantPlatform{
+				{id: 1, backend: models.BackendOpenCL, name: "Portable Computing Language",
+					version: "OpenCL 3.0 PoCL 6.0  Linux, Release, RELOC, SPIR-V, LLVM 18.1.8, SLEEF, DISTRO, POCL_DEBUG", devices: []int{1}},
+			},
+			devices: []wantDevice{
+				{id: 1, backend: models.BackendOpenCL, deviceType: "CPU", name: "cpu-haswell-AMD Ryzen 9 3900X 12-Core Processor", memoryTotal: 29934},
+			},
+		},
+		{
+			file: "devices_cuda.txt",
+			platforms: []wantPlatform{
+				{id: 0, backend: models.BackendCUDA, name: "CUDA", version: "12.2", devices: []int{1}},
+				{id: 1, backend: models.BackendOpenCL, name: "Portable Computing Language",
+					version: "OpenCL 3.0 PoCL 5.0  Linux, Release, RELOC, LLVM 16.0.6, SLEEF, DISTRO, POCL_DEBUG", devices: []int{2}},
+				{id: 2, backend: models.BackendOpenCL, name: "NVIDIA CUDA", version: "OpenCL 3.0 CUDA 12.2.138", devices: []int{3}},
+			},
+			devices: []wantDevice{
+				{id: 1, backend: models.BackendCUDA, deviceType: "GPU", name: "NVIDIA GeForce RTX 3080", memoryTotal: 10009,
+					pciAddress: "0000:01:00.0", aliases: []int{3}},
+				{id: 2, backend: models.BackendOpenCL, deviceType: "CPU",
+					name: "cpu-skylake-avx512-Intel(R) Core(TM) i9-10900K CPU @ 3.70GHz", memoryTotal: 30000},
+				{id: 3, backend: models.BackendOpenCL, deviceType: "GPU", name: "NVIDIA GeForce RTX 3080", memoryTotal: 10009,
+					pciAddress: "01:00.0", aliases: []int{1}, skipped: true},
+			},
+		},
+		{
+			file: "devices_hip.txt",
+			platforms: []wantPlatform{
+				{id: 0, backend: models.BackendHIP, name: "HIP", version: "5.7.31921", devices: []int{1}},
+				{id: 1, backend: models.BackendOpenCL, name: "AMD Accelerated Parallel Processing", version: "OpenCL 2.1 AMD-APP (3590.0)", devices: []int{2}},
+			},
+			devices: []wantDevice{
+				{id: 1, backend: models.BackendHIP, deviceType: "GPU", name: "AMD Radeon RX 6800 XT", memoryTotal: 16368,
+					pciAddress: "0000:03:00.0", aliases: []int{2}},
+				{id: 2, backend: models.BackendOpenCL, deviceType: "GPU", name: "gfx1030", memoryTotal: 16368,
+					pciAddress: "03:00.0", aliases: []int{1}, skipped: true},
+			},
+		},
+		{
+			file: "devices_metal.txt",
+			platforms: []wantPlatform{
+				{id: 0, backend: models.BackendMetal, name: "Metal", version: "343.19", devices: []int{1}},
+				{id: 1, backend: models.BackendOpenCL, name: "Apple", version: "OpenCL 1.2 (Apr 19 2022 18:44:44)", devices: []int{2, 3}},
+			},
+			devices: []wantDevice{
+				{id: 1, backend: models.BackendMetal, deviceType: "GPU", name: "Apple M1 Pro", memoryTotal: 21845},
+				{id: 2, backend: models.BackendOpenCL, deviceType: "CPU", name: "Apple M1 Pro", memoryTotal: 32768, skipped: true},
+				{id: 3, backend: models.BackendOpenCL, deviceType: "GPU", name: "Apple M1 Pro", memoryTotal: 21845, aliases: []int{1}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			output, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			list, err := ParseDeviceOutput(string(output))
+			if err != nil {
+				t.Fatalf("ParseDeviceOutput() error = %v", err)
+			}
+
+			if len(list.Platforms) != len(tt.platforms) {
+				t.Fatalf("got %d platforms, want %d", len(list.Platforms), len(tt.platforms))
+			}
+
+			for i, want := range tt.platforms {
+				got := list.Platforms[i]
+				var ids []int
+				for _, device := range got.Devices {
+					ids = append(ids, device.ID)
+				}
+
+				if got.ID != want.id || got.Backend != want.backend || got.Name != want.name ||
+					got.Version != want.version || !reflect.DeepEqual(ids, want.devices) {
+					t.Errorf("platform %d = {ID: %d, Backend: %s, Name: %q, Version: %q, Devices: %v}, want %+v",
+						i, got.ID, got.Backend, got.Name, got.Version, ids, want)
+				}
+			}
+
+			devices := list.Devices()
+			if len(devices) != len(tt.devices) {
+				t.Fatalf("got %d devices, want %d", len(devices), len(tt.devices))
+			}
+
+			for i, want := range tt.devices {
+				got := devices[i]
+				if got.ID != want.id || got.Backend != want.backend || got.Type != want.deviceType ||
+					got.Name != want.name || got.MemoryTotal != want.memoryTotal || got.PCIAddress != want.pciAddress ||
+					!reflect.DeepEqual(got.Aliases, want.aliases) || got.Skipped != want.skipped {
+					t.Errorf("device #%d = {Backend: %s, Type: %q, Name: %q, MemoryTotal: %d, PCIAddress: %q, Aliases: %v, Skipped: %t}, want %+v",
+						got.ID, got.Backend, got.Type, got.Name, got.MemoryTotal, got.PCIAddress, got.Aliases, got.Skipped, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDeviceOutputEmpty(t *testing.T) {
+	_, err := ParseDeviceOutput("hashcat (v6.2.6) starting in backend information mode\n")
+	if !errors.Is(err, ErrNoDevices) {
+		t.Errorf("ParseDeviceOutput() error = %v, want %v", err, ErrNoDevices)
+	}
+}
+
+func TestCheckDeviceIDs(t *testing.T) {
+	output, err := os.ReadFile(filepath.Join("testdata", "devices_cuda.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := ParseDeviceOutput(string(output))
+	if err != nil {
+		t.Fatalf("ParseDeviceOutput() error = %v", err)
+	}
+
+	tests := []struct {
+		ids     []int
+		wantErr bool
+	}{
+		{ids: []int{1, 2}},
+		{ids: []int{3}, wantErr: true}, // Skipped alias of the CUDA device
+		{ids: []int{4}, wantErr: true}, // Not listed
+	}
+
+	for _, tt := range tests {
+		err := checkDeviceIDs(list, tt.ids)
+		if tt.wantErr != (err != nil) || (err != nil && !errors.Is(err, ErrInvalidDevice)) {
+			t.Errorf("checkDeviceIDs(%v) error = %v, want error %t", tt.ids, err, tt.wantErr)
+		}
+	}
+}
@@ -7,14 +7,46 @@ import (
 
 // Custom error values for various scenarios
 var (
-	ErrInvalidBinaryPath = errors.New("invalid hashcat binary path")
-	ErrInvalidOutputDir  = errors.New("invalid output directory")
-	ErrInvalidAttackMode = errors.New("invalid attack mode, must be between 0 and 9")
-	ErrInvalidHashType   = errors.New("invalid hash type")
-	ErrBinaryNotFound    = errors.New("hashcat binary not found or not executable")
-	ErrExecutionFailed   = errors.New("hashcat execution failed")
-	ErrInvalidHash       = errors.New("invalid hash format")
-	ErrInvalidHashFile   = errors.New("invalid hash file")
+	ErrInvalidBinaryPath   = errors.New("invalid hashcat binary path")
+	ErrInvalidOutputDir    = errors.New("invalid output directory")
+	ErrInvalidAttackMode   = errors.New("invalid attack mode, must be between 0 and 9")
+	ErrInvalidHashType     = errors.New("invalid hash type")
+	ErrInvalidStopTimeout  = errors.New("invalid stop timeout, must be positive")
+	ErrInvalidPotfilePath  = errors.New("invalid potfile path")
+	ErrBinaryNotFound      = errors.New("hashcat binary not found or not executable")
+	ErrExecutionFailed     = errors.New("hashcat execution failed")
+	ErrInvalidHash         = errors.New("invalid hash format")
+	ErrInvalidHashFile     = errors.New("invalid hash file")
+	ErrUnknownHashType     = errors.New("hash type could not be identified")
+	ErrPasswordLength      = errors.New("password length not supported by hash type")
+	ErrInvalidOutfileLine  = errors.New("invalid outfile line")
+	ErrInvalidAttack       = errors.New("invalid attack")
+	ErrInvalidCrackOptions = errors.New("invalid crack options")
+	ErrInvalidDevice       = errors.New("invalid device selection")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrInvalidSessionName  = errors.New("invalid session name")
+	ErrSessionExists       = errors.New("session already exists")
+	ErrSessionNotRunning   = errors.New("session is not running")
+)
+
+// Errors for the ways a hashcat process can end, as told by its exit code
+var (
+	ErrExhausted         = errors.New("keyspace exhausted without cracking all hashes")
+	ErrAborted           = errors.New("hashcat was aborted")
+	ErrAbortedCheckpoint = errors.New("hashcat quit at a checkpoint")
+	ErrAbortedRuntime    = errors.New("hashcat reached its runtime limit")
+	ErrAbortedFinish     = errors.New("hashcat quit after finishing the current attack")
+	ErrWatchdogAbort     = errors.New("hashcat was aborted by the temperature watchdog")
+	ErrGuardrailAbort    = errors.New("session was aborted by a guardrail")
+)
+
+// Errors recognized in the messages hashcat prints before failing
+var (
+	ErrTokenLength    = errors.New("token length exception")
+	ErrNoHashesLoaded = errors.New("no hashes loaded")
+	ErrOutOfMemory    = errors.New("out of device memory")
+	ErrKernelBuild    = errors.New("kernel build failed")
+	ErrNoDevices      = errors.New("no usable devices found")
 )
 
 // HashcatError represents a specific hashcat error with context
@@ -22,14 +54,20 @@ type HashcatError struct {
 	Operation string // The operation that failed
 	Err       error  // The underlying error
 	Output    string // Command output if available
+	ExitCode  int    // Exit code of hashcat, if it exited
 }
 
 // Error implements the error interface
 func (e *HashcatError) Error() string {
+	outcome := "failed"
+	if IsStopOutcome(e.Err) {
+		outcome = "stopped"
+	}
+
 	if e.Output != "" {
-		return fmt.Sprintf("hashcat %s failed: %v - output: %s", e.Operation, e.Err, e.Output)
+		return fmt.Sprintf("hashcat %s %s: %v - output: %s", e.Operation, outcome, e.Err, e.Output)
 	}
-	return fmt.Sprintf("hashcat %s failed: %v", e.Operation, e.Err)
+	return fmt.Sprintf("hashcat %s %s: %v", e.Operation, outcome, e.Err)
 }
 
 // Unwrap implements the error unwrapping interface
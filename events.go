@@ -0,0 +1,192 @@
+package hashcat
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pixelsquared/go-hashcat/models"
+)
+
+// EventType identifies the kind of a session event
+type EventType int
+
+const (
+	EventStarted   EventType = iota + 1 // hashcat was started
+	EventProgress                       // hashcat reported its status
+	EventCracked                        // A hash was cracked
+	EventWarning                        // hashcat printed a message that is not a status update
+	EventError                          // The session ran into an error
+	EventPaused                         // The session was paused
+	EventResumed                        // The session was resumed
+	EventFinished                       // hashcat exited and all output was read; always the last event
+	EventGuardrail                      // A guardrail paused, resumed or aborted the session
+)
+
+// String returns the name of the event type
+func (t EventType) String() string {
+	switch t {
+	case EventStarted:
+		return "started"
+	case EventProgress:
+		return "progress"
+	case EventCracked:
+		return "cracked"
+	case EventWarning:
+		return "warning"
+	case EventError:
+		return "error"
+	case EventPaused:
+		return "paused"
+	case EventResumed:
+		return "resumed"
+	case EventFinished:
+		return "finished"
+	case EventGuardrail:
+		return "guardrail"
+	}
+	return "unknown"
+}
+
+// Event is an entry of the event stream of a session. Only the fields matching the
+// event type are set.
+type Event struct {
+	Type      EventType
+	Time      time.Time
+	Session   string              // Name of the session
+	Progress  *models.Progress    // Status update, for EventProgress
+	Cracked   *models.CrackedHash // Cracked hash, for EventCracked
+	Message   string              // Line printed by hashcat, for EventWarning, or why a guardrail acted, for EventGuardrail
+	Err       error               // The error, for EventError
+	Exit      *ExitStatus         // How hashcat exited, for EventFinished
+	Guardrail *GuardrailEvent     // The action taken, for EventGuardrail
+}
+
+// ExitStatus describes how a hashcat process ended
+type ExitStatus struct {
+	Code       int                   // Exit code of hashcat (0=cracked, 1=exhausted, 2-5=aborted, -1=error, -2=watchdog)
+	Signaled   bool                  // Whether hashcat was terminated by a signal, in which case Code is -1
+	Status     models.CrackingStatus // Last status of the session
+	Cracked    int                   // Number of hashes cracked by the session
+	Restorable bool                  // Whether hashcat left a restore file behind
+	Err        error                 // How hashcat exited unless it cracked every hash, see Wait
+}
+
+// eventStream queues the events of a session for a single reader. Events are only
+// recorded once the stream has been opened, and are never dropped: they are queued
+// without limit until they are read, so that emitting never blocks the session.
+// Delivery only begins once the session has started, so a session that is never
+// started does not leave a goroutine behind.
+type eventStream struct {
+	mutex      sync.Mutex
+	cond       *sync.Cond
+	queue      []*Event
+	out        chan *Event
+	started    bool
+	delivering bool
+	closed     bool
+}
+
+// newEventStream returns a stream that has not been opened yet
+func newEventStream() *eventStream {
+	stream := &eventStream{}
+	stream.cond = sync.NewCond(&stream.mutex)
+	return stream
+}
+
+// open returns the channel events are delivered on, delivering them right away if the
+// session has already started
+func (e *eventStream) open() <-chan *Event {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.out == nil {
+		e.out = make(chan *Event)
+		if e.closed {
+			close(e.out)
+		} else if e.started {
+			e.startDelivery()
+		}
+	}
+
+	return e.out
+}
+
+// start begins delivering events once the session has started
+func (e *eventStream) start() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.started = true
+	if e.out != nil && !e.closed {
+		e.startDelivery()
+	}
+}
+
+// startDelivery starts the goroutine sending events to the reader. The caller must hold
+// the mutex.
+func (e *eventStream) startDelivery() {
+	if !e.delivering {
+		e.delivering = true
+		go e.deliver()
+	}
+}
+
+// emit queues an event, unless the stream is not open or already closed
+func (e *eventStream) emit(event *Event) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.out == nil || e.closed {
+		return
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	e.queue = append(e.queue, event)
+	e.cond.Signal()
+}
+
+// close stops accepting events. Queued events are still delivered before the channel
+// is closed; if delivery never started the channel is closed right away.
+func (e *eventStream) close() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.closed {
+		return
+	}
+	e.closed = true
+
+	if e.out != nil && !e.delivering {
+		e.queue = nil
+		close(e.out)
+		return
+	}
+
+	e.cond.Signal()
+}
+
+// deliver sends queued events to the reader until the stream is closed and empty
+func (e *eventStream) deliver() {
+	defer close(e.out)
+
+	for {
+		e.mutex.Lock()
+		for len(e.queue) == 0 && !e.closed {
+			e.cond.Wait()
+		}
+		if len(e.queue) == 0 {
+			e.mutex.Unlock()
+			return
+		}
+
+		event := e.queue[0]
+		e.queue[0] = nil
+		e.queue = e.queue[1:]
+		e.mutex.Unlock()
+
+		e.out <- event
+	}
+}
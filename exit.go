@@ -0,0 +1,195 @@
+package hashcat
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Exit codes of hashcat
+const (
+	exitWatchdog          = -2 // Aborted by the temperature watchdog
+	exitError             = -1 // Failed
+	exitCracked           = 0  // All hashes cracked
+	exitExhausted         = 1  // Keyspace exhausted
+	exitAborted           = 2  // Aborted by the user or a signal
+	exitAbortedCheckpoint = 3  // Quit at a checkpoint
+	exitAbortedRuntime    = 4  // Reached --runtime
+	exitAbortedFinish     = 5  // Quit after finishing the current attack
+)
+
+// maxOutputLines is the number of hashcat's last messages kept for errors
+const maxOutputLines = 20
+
+// outputRule recognizes a message hashcat prints before failing
+type outputRule struct {
+	text string // Lowercase text contained in the message
+	err  error
+}
+
+// outputRules are checked against every message hashcat prints besides status updates
+var outputRules = []outputRule{
+	{"token length exception", ErrTokenLength},
+	{"separator unmatched", ErrInvalidHash},
+	{"line-length exception", ErrInvalidHash},
+	{"hash-value exception", ErrInvalidHash},
+	{"salt-length exception", ErrInvalidHash},
+	{"no hashes loaded", ErrNoHashesLoaded},
+	{"not enough allocatable device memory", ErrOutOfMemory},
+	{"cl_mem_object_allocation_failure", ErrOutOfMemory},
+	{"cl_out_of_resources", ErrOutOfMemory},
+	{"cuda_error_out_of_memory", ErrOutOfMemory},
+	{"out of memory", ErrOutOfMemory},
+	{"cl_build_program_failure", ErrKernelBuild},
+	{"nvrtccompileprogram", ErrKernelBuild},
+	{"hiprtccompileprogram", ErrKernelBuild},
+	{"build failed", ErrKernelBuild},
+	{"no devices found/left", ErrNoDevices},
+	{"compatible platform found", ErrNoDevices},
+	{"already an instance", ErrSessionExists},
+}
+
+// classifyMessage returns the error a message printed by hashcat stands for, or nil
+// when the message is not recognized
+func classifyMessage(message string) error {
+	message = strings.ToLower(message)
+	for _, rule := range outputRules {
+		if strings.Contains(message, rule.text) {
+			return rule.err
+		}
+	}
+	return nil
+}
+
+// outputClassifier collects the messages printed by hashcat, recognizing known failures
+// as they are printed and keeping the last few messages for context
+type outputClassifier struct {
+	mutex  sync.Mutex
+	causes []error
+	lines  []string
+}
+
+// add records a message printed by hashcat
+func (c *outputClassifier) add(message string) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := classifyMessage(message); err != nil && !containsError(c.causes, err) {
+		c.causes = append(c.causes, err)
+	}
+
+	c.lines = append(c.lines, message)
+	if len(c.lines) > maxOutputLines {
+		c.lines = c.lines[len(c.lines)-maxOutputLines:]
+	}
+}
+
+// addOutput records each line of a command's output
+func (c *outputClassifier) addOutput(output string) {
+	for _, line := range strings.Split(output, "\n") {
+		c.add(line)
+	}
+}
+
+// result returns the recognized failures in the order they were first printed, and
+// the last messages
+func (c *outputClassifier) result() ([]error, string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]error(nil), c.causes...), strings.Join(c.lines, "\n")
+}
+
+// containsError reports whether errs contains err
+func containsError(errs []error, err error) bool {
+	for _, e := range errs {
+		if e == err {
+			return true
+		}
+	}
+	return false
+}
+
+// exitCode returns hashcat's exit code from the error returned by waiting for it, and
+// whether it was terminated by a signal. Negative exit codes are restored from the
+// unsigned values the operating system reports.
+func exitCode(err error) (int, bool) {
+	if err == nil {
+		return exitCracked, false
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return exitError, false
+	}
+
+	code := exitErr.ExitCode()
+	if code == -1 {
+		return exitError, true
+	}
+
+	return int(int8(code)), false
+}
+
+// classifyExit classifies how a hashcat process ended, from the error returned by waiting
+// for it and the messages it printed. It returns nil when hashcat exited successfully,
+// and otherwise a *HashcatError wrapping one of the exit sentinels, or for failures the
+// errors recognized in the output, falling back to ErrExecutionFailed.
+func classifyExit(operation string, waitErr error, output *outputClassifier) error {
+	if waitErr == nil {
+		return nil
+	}
+
+	code, signaled := exitCode(waitErr)
+	hcErr := &HashcatError{Operation: operation, ExitCode: code}
+
+	var exitErr *exec.ExitError
+	switch {
+	case !errors.As(waitErr, &exitErr):
+		hcErr.Err = fmt.Errorf("%w: %w", ErrExecutionFailed, waitErr)
+	case signaled:
+		hcErr.Err = fmt.Errorf("%w: %v", ErrAborted, waitErr)
+	case code == exitExhausted:
+		hcErr.Err = ErrExhausted
+	case code == exitAborted:
+		hcErr.Err = ErrAborted
+	case code == exitAbortedCheckpoint:
+		hcErr.Err = ErrAbortedCheckpoint
+	case code == exitAbortedRuntime:
+		hcErr.Err = ErrAbortedRuntime
+	case code == exitAbortedFinish:
+		hcErr.Err = ErrAbortedFinish
+	case code == exitWatchdog:
+		hcErr.Err = ErrWatchdogAbort
+		_, hcErr.Output = output.result()
+	default:
+		causes, lines := output.result()
+		if len(causes) == 0 {
+			causes = []error{ErrExecutionFailed}
+		}
+		hcErr.Err = errors.Join(causes...)
+		hcErr.Output = lines
+	}
+
+	return hcErr
+}
+
+// IsStopOutcome reports whether an error returned for a hashcat process only tells why
+// it stopped working, rather than that it failed: the keyspace was exhausted or the
+// session was aborted, quit at a checkpoint, reached its runtime or finished its attack.
+// Results of such a session are complete up to the point where it stopped.
+func IsStopOutcome(err error) bool {
+	for _, outcome := range []error{ErrExhausted, ErrAborted, ErrAbortedCheckpoint, ErrAbortedRuntime, ErrAbortedFinish} {
+		if errors.Is(err, outcome) {
+			return true
+		}
+	}
+	return false
+}
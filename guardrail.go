@@ -0,0 +1,366 @@
+package hashcat
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pixelsquared/go-hashcat/models"
+)
+
+// Defaults of a GuardrailPolicy
+const (
+	defaultGuardrailSamples = 3
+	defaultCooldownMargin   = 10
+)
+
+// GuardrailAction is an action taken by the guardrails of a session
+type GuardrailAction int
+
+const (
+	GuardrailPause  GuardrailAction = iota + 1 // The session was paused to let a device cool down
+	GuardrailResume                            // The session was resumed once every device cooled down
+	GuardrailAbort                             // The session was aborted
+)
+
+// String returns the name of the action
+func (a GuardrailAction) String() string {
+	switch a {
+	case GuardrailPause:
+		return "pause"
+	case GuardrailResume:
+		return "resume"
+	case GuardrailAbort:
+		return "abort"
+	}
+	return fmt.Sprintf("GuardrailAction(%d)", int(a))
+}
+
+// GuardrailPolicy protects the hardware running a session. It is evaluated on each
+// progress update, so the number of samples is counted in status updates. Temperatures
+// are read from hashcat's hardware monitor, which must not be disabled.
+type GuardrailPolicy struct {
+	PauseTemperature  int           // Pause when a device reaches this temperature in °C (0=never pause)
+	ResumeTemperature int           // Resume once every device with a reading has cooled to this temperature (0=PauseTemperature-10)
+	PauseSamples      int           // Consecutive samples a device must be hot before pausing (0=3)
+	ResumeSamples     int           // Consecutive samples every device must be cool before resuming (0=3)
+	MaxPause          time.Duration // Abort when the devices have not cooled down after this long (0=no limit)
+
+	AbortTemperature int // Abort when a device reaches this temperature in °C (0=never)
+	AbortSamples     int // Consecutive samples a device must be that hot before aborting (0=3)
+
+	// StallSamples aborts the session after this many consecutive samples in which a
+	// running device reports no utilization, which happens when a kernel is stuck (0=never)
+	StallSamples int
+}
+
+// Validate checks that the thresholds are consistent and that at least one guardrail
+// is enabled
+func (p *GuardrailPolicy) Validate() error {
+	for _, value := range []struct {
+		name  string
+		value int
+	}{
+		{"pause temperature", p.PauseTemperature},
+		{"resume temperature", p.ResumeTemperature},
+		{"pause samples", p.PauseSamples},
+		{"resume samples", p.ResumeSamples},
+		{"abort temperature", p.AbortTemperature},
+		{"abort samples", p.AbortSamples},
+		{"stall samples", p.StallSamples},
+	} {
+		if value.value < 0 {
+			return fmt.Errorf("%w: guardrail %s must not be negative", ErrInvalidCrackOptions, value.name)
+		}
+	}
+
+	if p.MaxPause < 0 {
+		return fmt.Errorf("%w: guardrail maximum pause must not be negative", ErrInvalidCrackOptions)
+	}
+
+	if p.PauseTemperature == 0 && (p.ResumeTemperature != 0 || p.MaxPause != 0) {
+		return fmt.Errorf("%w: guardrail resume temperature and maximum pause require a pause temperature", ErrInvalidCrackOptions)
+	}
+
+	if p.PauseTemperature != 0 && p.ResumeTemperature >= p.PauseTemperature {
+		return fmt.Errorf("%w: guardrail resume temperature must be below the pause temperature", ErrInvalidCrackOptions)
+	}
+
+	if p.PauseTemperature != 0 && p.resumeTemperature() <= 0 {
+		return fmt.Errorf("%w: guardrail resume temperature %d°C is not above zero", ErrInvalidCrackOptions, p.resumeTemperature())
+	}
+
+	if p.PauseTemperature != 0 && p.AbortTemperature != 0 && p.AbortTemperature <= p.PauseTemperature {
+		return fmt.Errorf("%w: guardrail abort temperature must be above the pause temperature", ErrInvalidCrackOptions)
+	}
+
+	if p.PauseTemperature == 0 && p.AbortTemperature == 0 && p.StallSamples == 0 {
+		return fmt.Errorf("%w: guardrail policy enables no guardrail", ErrInvalidCrackOptions)
+	}
+
+	return nil
+}
+
+// resumeTemperature returns the temperature devices must cool to, with its default applied
+func (p *GuardrailPolicy) resumeTemperature() int {
+	if p.ResumeTemperature == 0 {
+		return p.PauseTemperature - defaultCooldownMargin
+	}
+	return p.ResumeTemperature
+}
+
+// watchesTemperature reports whether the policy needs temperature readings
+func (p *GuardrailPolicy) watchesTemperature() bool {
+	return p.PauseTemperature != 0 || p.AbortTemperature != 0
+}
+
+// GuardrailEvent explains an action taken by the guardrails, for EventGuardrail
+type GuardrailEvent struct {
+	Action      GuardrailAction
+	DeviceID    int    // Device that triggered the action, 0 when it concerns every device
+	Temperature int    // Temperature of the device in °C, or models.Unavailable
+	Utilization int    // Utilization of the device in percent, or models.Unavailable
+	Samples     int    // Consecutive samples the condition held for
+	Reason      string // Explanation of the action
+}
+
+// guardrail evaluates a policy against the progress updates of a session. It is only
+// used from the goroutine reading hashcat's output.
+type guardrail struct {
+	policy GuardrailPolicy
+
+	hot      map[int]int // Consecutive samples at the pause temperature, by device
+	critical map[int]int // Consecutive samples at the abort temperature, by device
+	stalled  map[int]int // Consecutive running samples without utilization, by device
+	cool     int         // Consecutive samples with every device cool, while paused
+
+	paused    bool // Whether the guardrail paused the session
+	pauseSeen bool // Whether hashcat reported the session as paused since the guardrail paused it
+	pausedAt  time.Time
+	aborted   bool
+}
+
+// newGuardrail returns a guardrail for the policy with its defaults applied, or nil
+// without a policy
+func newGuardrail(policy *GuardrailPolicy) *guardrail {
+	if policy == nil {
+		return nil
+	}
+
+	g := &guardrail{
+		policy:   *policy,
+		hot:      make(map[int]int),
+		critical: make(map[int]int),
+		stalled:  make(map[int]int),
+	}
+
+	g.policy.ResumeTemperature = g.policy.resumeTemperature()
+	for _, samples := range []*int{&g.policy.PauseSamples, &g.policy.ResumeSamples, &g.policy.AbortSamples} {
+		if *samples == 0 {
+			*samples = defaultGuardrailSamples
+		}
+	}
+
+	return g
+}
+
+// evaluate records a progress update and returns the action to take, or nil
+func (g *guardrail) evaluate(progress *models.Progress, now time.Time) *GuardrailEvent {
+	if g.aborted {
+		return nil
+	}
+
+	running := progress.Status == models.StatusRunning
+
+	// Follow the pause from hashcat's status, as the session may have been resumed
+	// by someone else since the guardrail paused it
+	if g.paused {
+		switch {
+		case progress.Status == models.StatusPaused:
+			g.pauseSeen = true
+		case g.pauseSeen:
+			g.releasePause()
+		}
+	}
+
+	var hottest *GuardrailEvent
+	readings := 0
+	allCool := true
+	for _, device := range progress.Devices {
+		// hashcat omits the temperature when it cannot read it, as for most CPUs;
+		// such devices do not keep the session from resuming
+		temperature := device.Temperature
+		if temperature <= 0 {
+			temperature = models.Unavailable
+		} else {
+			readings++
+			if temperature > g.policy.ResumeTemperature {
+				allCool = false
+			}
+		}
+
+		g.critical[device.DeviceID] = countIf(g.critical[device.DeviceID],
+			g.policy.AbortTemperature != 0 && temperature >= g.policy.AbortTemperature)
+		g.hot[device.DeviceID] = countIf(g.hot[device.DeviceID],
+			g.policy.PauseTemperature != 0 && temperature >= g.policy.PauseTemperature)
+		g.stalled[device.DeviceID] = countIf(g.stalled[device.DeviceID],
+			g.policy.StallSamples != 0 && running && device.Utilization == 0)
+
+		if samples := g.critical[device.DeviceID]; samples >= g.policy.AbortSamples {
+			return g.abort(&GuardrailEvent{
+				DeviceID:    device.DeviceID,
+				Temperature: temperature,
+				Utilization: device.Utilization,
+				Samples:     samples,
+				Reason: fmt.Sprintf("device #%d reached %d°C for %d samples, abort threshold is %d°C",
+					device.DeviceID, temperature, samples, g.policy.AbortTemperature),
+			})
+		}
+
+		if samples := g.stalled[device.DeviceID]; g.policy.StallSamples != 0 && samples >= g.policy.StallSamples {
+			return g.abort(&GuardrailEvent{
+				DeviceID:    device.DeviceID,
+				Temperature: temperature,
+				Utilization: device.Utilization,
+				Samples:     samples,
+				Reason: fmt.Sprintf("device #%d reported no utilization for %d samples, its kernel is likely stuck",
+					device.DeviceID, samples),
+			})
+		}
+
+		if samples := g.hot[device.DeviceID]; samples >= g.policy.PauseSamples && (hottest == nil || temperature > hottest.Temperature) {
+			hottest = &GuardrailEvent{
+				DeviceID:    device.DeviceID,
+				Temperature: temperature,
+				Utilization: device.Utilization,
+				Samples:     samples,
+			}
+		}
+	}
+
+	if !g.paused {
+		// Leave sessions paused by someone else alone
+		if hottest == nil || progress.Status == models.StatusPaused {
+			return nil
+		}
+
+		g.paused = true
+		g.pauseSeen = false
+		g.pausedAt = now
+		g.cool = 0
+
+		hottest.Action = GuardrailPause
+		hottest.Reason = fmt.Sprintf("device #%d reached %d°C for %d samples, pause threshold is %d°C; resuming once every device is at %d°C or less",
+			hottest.DeviceID, hottest.Temperature, hottest.Samples, g.policy.PauseTemperature, g.policy.ResumeTemperature)
+		return hottest
+	}
+
+	g.cool = countIf(g.cool, allCool && readings > 0)
+	if g.cool >= g.policy.ResumeSamples {
+		samples := g.cool
+		g.releasePause()
+
+		return &GuardrailEvent{
+			Action:      GuardrailResume,
+			Temperature: models.Unavailable,
+			Utilization: models.Unavailable,
+			Samples:     samples,
+			Reason: fmt.Sprintf("every device cooled to %d°C or less for %d samples after %s",
+				g.policy.ResumeTemperature, samples, now.Sub(g.pausedAt).Round(time.Second)),
+		}
+	}
+
+	if g.policy.MaxPause != 0 && now.Sub(g.pausedAt) >= g.policy.MaxPause {
+		return g.abort(&GuardrailEvent{
+			Temperature: models.Unavailable,
+			Utilization: models.Unavailable,
+			Reason: fmt.Sprintf("devices did not cool to %d°C within %s of pausing",
+				g.policy.ResumeTemperature, g.policy.MaxPause),
+		})
+	}
+
+	return nil
+}
+
+// releasePause forgets the pause of the guardrail, so that a device has to be hot for
+// PauseSamples again before the session is paused
+func (g *guardrail) releasePause() {
+	g.paused = false
+	g.pauseSeen = false
+	g.cool = 0
+	for id := range g.hot {
+		g.hot[id] = 0
+	}
+}
+
+// abort marks the guardrail as done and returns the abort action
+func (g *guardrail) abort(event *GuardrailEvent) *GuardrailEvent {
+	g.aborted = true
+	event.Action = GuardrailAbort
+	return event
+}
+
+// countIf returns count incremented when the condition holds, or reset otherwise
+func countIf(count int, condition bool) int {
+	if condition {
+		return count + 1
+	}
+	return 0
+}
+
+// enforceGuardrails evaluates the guardrails against a progress update and takes the
+// action they call for, emitting an EventGuardrail explaining it
+func (s *HashcatCrackSession) enforceGuardrails(progress *models.Progress) {
+	if s.guardrail == nil {
+		return
+	}
+
+	action := s.guardrail.evaluate(progress, time.Now())
+	if action == nil {
+		return
+	}
+
+	s.emit(&Event{Type: EventGuardrail, Message: action.Reason, Guardrail: action})
+
+	var err error
+	switch action.Action {
+	case GuardrailPause:
+		if err = s.Pause(); err != nil {
+			s.guardrail.releasePause()
+		}
+	case GuardrailResume:
+		err = s.Resume()
+	case GuardrailAbort:
+		err = s.abortForGuardrail(fmt.Errorf("%w: %s", ErrGuardrailAbort, action.Reason))
+	}
+
+	if err != nil {
+		s.emit(&Event{Type: EventError, Err: fmt.Errorf("guardrail could not %s the session: %w", action.Action, err)})
+	}
+}
+
+// abortForGuardrail asks hashcat to quit right away, writing its restore file, and
+// terminates it if it has not exited within the stop timeout. Wait then returns err.
+func (s *HashcatCrackSession) abortForGuardrail(err error) error {
+	s.mutex.Lock()
+	s.guardrailErr = err
+	exited := s.exited
+	s.mutex.Unlock()
+
+	// A stuck kernel may keep hashcat from reading the key, so do not wait on the
+	// goroutine reading its output
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), s.client.config.StopTimeout)
+		defer cancel()
+
+		if err := s.sendKeyContext(ctx, keyQuit); err != nil {
+			ctx = canceledContext()
+		}
+
+		if _, err := s.awaitExit(ctx, exited); err != nil {
+			s.emit(&Event{Type: EventError, Err: err})
+		}
+	}()
+
+	return nil
+}
@@ -56,6 +56,15 @@
 //	    "?a?a?a?a?a?a?a?a",                 // Mask: 8 chars, all character sets
 //	)
 //
+// Attacks that need more than one input use the typed attacks:
+//
+//	progressChan, err := client.CrackWithAttack(
+//	    context.Background(),
+//	    "5f4dcc3b5aa765d61d8327deb882cf99",
+//	    0,
+//	    &hashcat.HybridWordlistMaskAttack{Wordlist: "words.txt", Mask: "?d?d"},
+//	)
+//
 // # Design Principles
 //
 // The hashcat package follows these design principles:
@@ -102,7 +111,9 @@
 //
 // Errors are wrapped with context to provide more information about the operation that failed.
 // Custom error types like HashcatError provide details about the command that failed and
-// the output from hashcat.
+// the output from hashcat. The exit code of hashcat and the messages it prints are
+// classified into sentinel errors such as ErrExhausted and ErrTokenLength, which can be
+// matched with errors.Is.
 //
 // # Examples
 //
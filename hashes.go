@@ -3,17 +3,74 @@ package hashcat
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/pixelsquared/go-hashcat/mask"
 	"github.com/pixelsquared/go-hashcat/models"
 )
 
+// hashInfo is the JSON description of a hash mode printed by hashcat --hash-info --machine-readable
+type hashInfo struct {
+	Name              string     `json:"name"`
+	Category          string     `json:"category"`
+	SlowHash          bool       `json:"slow_hash"`
+	PasswordLenMin    int        `json:"password_len_min"`
+	PasswordLenMax    int        `json:"password_len_max"`
+	IsSalted          bool       `json:"is_salted"`
+	SaltType          string     `json:"salt_type"`
+	SaltLenMin        int        `json:"salt_len_min"`
+	SaltLenMax        int        `json:"salt_len_max"`
+	KernelType        stringList `json:"kernel_type"`
+	ExampleHashFormat string     `json:"example_hash_format"`
+	ExampleHash       string     `json:"example_hash"`
+	ExamplePass       string     `json:"example_pass"`
+	BenchmarkMask     string     `json:"benchmark_mask"`
+	BenchmarkCharset1 string     `json:"benchmark_charset1"`
+	AutodetectEnabled bool       `json:"autodetect_enabled"`
+	SelfTestEnabled   bool       `json:"self_test_enabled"`
+	PotfileEnabled    bool       `json:"potfile_enabled"`
+	CustomPlugin      bool       `json:"custom_plugin"`
+	PlaintextEncoding stringList `json:"plaintext_encoding"`
+	DeprecatedNotice  string     `json:"deprecated_notice"`
+}
+
+// stringList decodes a JSON array of strings, or a single string as a list of one
+type stringList []string
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = nil
+		if value := notAvailable(single); value != "" {
+			*l = stringList{value}
+		}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// notAvailable maps the "N/A" hashcat prints for missing values to an empty string
+func notAvailable(value string) string {
+	if value == "N/A" {
+		return ""
+	}
+	return value
+}
+
 // ParseHashInfoOutput parses the JSON output from hashcat --hash-info command
 // This is exported so it can be used in client.go
 func ParseHashInfoOutput(output string) (*models.HashcatSupportedHashes, error) {
 	// Parse the raw JSON output
-	var rawData map[string]map[string]interface{}
+	var rawData map[string]hashInfo
 	if err := json.Unmarshal([]byte(output), &rawData); err != nil {
 		return nil, fmt.Errorf("failed to parse hash info JSON: %w", err)
 	}
@@ -21,33 +78,68 @@ func ParseHashInfoOutput(output string) (*models.HashcatSupportedHashes, error)
 	// Convert the raw data to HashType structs
 	hashTypes := make([]models.HashType, 0, len(rawData))
 
-	for hashIDStr, hashData := range rawData {
+	for hashIDStr, info := range rawData {
 		// Convert hash ID from string to int
 		hashID, err := strconv.Atoi(hashIDStr)
 		if err != nil {
 			return nil, fmt.Errorf("invalid hash ID format '%s': %w", hashIDStr, err)
 		}
 
-		// Extract the hash type information
-		name, _ := hashData["name"].(string)
-		category, _ := hashData["category"].(string)
-
 		// Create HashType struct
 		hashType := models.HashType{
-			ID:          hashID,
-			Name:        name,
-			Category:    category,
-			Description: "", // Description field is not provided in the hash-info output
+			ID:                hashID,
+			Name:              info.Name,
+			Category:          info.Category,
+			Description:       "", // Description field is not provided in the hash-info output
+			SlowHash:          info.SlowHash,
+			IsSalted:          info.IsSalted,
+			SaltType:          notAvailable(info.SaltType),
+			SaltLenMin:        info.SaltLenMin,
+			SaltLenMax:        info.SaltLenMax,
+			PasswordLenMin:    info.PasswordLenMin,
+			PasswordLenMax:    info.PasswordLenMax,
+			KernelTypes:       info.KernelType,
+			ExampleHashFormat: notAvailable(info.ExampleHashFormat),
+			ExampleHash:       notAvailable(info.ExampleHash),
+			ExamplePass:       notAvailable(info.ExamplePass),
+			BenchmarkMask:     notAvailable(info.BenchmarkMask),
+			BenchmarkCharset1: notAvailable(info.BenchmarkCharset1),
+			AutodetectEnabled: info.AutodetectEnabled,
+			SelfTestEnabled:   info.SelfTestEnabled,
+			PotfileEnabled:    info.PotfileEnabled,
+			CustomPlugin:      info.CustomPlugin,
+			PlaintextEncoding: info.PlaintextEncoding,
+			DeprecatedNotice:  notAvailable(info.DeprecatedNotice),
 		}
 
 		hashTypes = append(hashTypes, hashType)
 	}
 
+	// Map iteration order is random, so sort for consistent results
+	sort.Slice(hashTypes, func(i, j int) bool {
+		return hashTypes[i].ID < hashTypes[j].ID
+	})
+
 	return &models.HashcatSupportedHashes{
 		HashTypes: hashTypes,
 	}, nil
 }
 
+// CheckMaskLength returns an error wrapping ErrPasswordLength when the candidates of a
+// mask are longer or shorter than the hash type supports
+func CheckMaskLength(hashType *models.HashType, m *mask.Mask) error {
+	switch {
+	case hashType.ExceedsPasswordLength(m.Len()):
+		return fmt.Errorf("%w: mask %q generates %d characters, %s supports at most %d",
+			ErrPasswordLength, m.String(), m.Len(), hashType.Name, hashType.PasswordLenMax)
+	case hashType.BelowPasswordLength(m.Len()):
+		return fmt.Errorf("%w: mask %q generates %d characters, %s requires at least %d",
+			ErrPasswordLength, m.String(), m.Len(), hashType.Name, hashType.PasswordLenMin)
+	default:
+		return nil
+	}
+}
+
 // FindHashTypeByID returns a HashType with the given ID or nil if not found
 func FindHashTypeByID(hashes *models.HashcatSupportedHashes, id int) *models.HashType {
 	for _, hashType := range hashes.HashTypes {
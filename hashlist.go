@@ -0,0 +1,251 @@
+package hashcat
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// HashEntry is a hash of a HashList with the account it belongs to
+type HashEntry struct {
+	Username string // Account the hash belongs to, written with --username
+	Hash     string // The hash itself
+	Salt     string // Raw salt appended after the separator, for modes with a separate salt
+}
+
+// HashList accumulates hashes to crack in a single session. It removes duplicate entries,
+// formats each line for the separator and tells the session whether --username and
+// --hex-salt are needed.
+type HashList struct {
+	Separator byte // Separator between username, hash and salt (-p, default ':')
+
+	entries []HashEntry
+	seen    map[HashEntry]bool
+}
+
+// NewHashList returns an empty hash list using the default separator
+func NewHashList() *HashList {
+	return &HashList{Separator: ':'}
+}
+
+// Add adds an entry to the list. Entries that are already in the list are ignored;
+// the same hash may still be added once for each username.
+func (l *HashList) Add(entry HashEntry) error {
+	separator := l.separator()
+
+	if entry.Hash == "" {
+		return fmt.Errorf("%w: empty hash", ErrInvalidHash)
+	}
+	if strings.ContainsAny(entry.Hash, "\r\n") || strings.ContainsAny(entry.Username, "\r\n") {
+		return fmt.Errorf("%w: line break in hash or username", ErrInvalidHash)
+	}
+	if strings.IndexByte(entry.Username, separator) >= 0 {
+		return fmt.Errorf("%w: username %q contains the separator %q", ErrInvalidHash, entry.Username, separator)
+	}
+
+	if l.seen == nil {
+		l.seen = make(map[HashEntry]bool)
+	}
+	if l.seen[entry] {
+		return nil
+	}
+
+	l.seen[entry] = true
+	l.entries = append(l.entries, entry)
+
+	return nil
+}
+
+// AddHash adds a hash without a username or salt
+func (l *HashList) AddHash(hash string) error {
+	return l.Add(HashEntry{Hash: hash})
+}
+
+// AddUserHash adds the hash of an account
+func (l *HashList) AddUserHash(username, hash string) error {
+	return l.Add(HashEntry{Username: username, Hash: hash})
+}
+
+// AddSaltedHash adds a hash with a separate salt. Salts that contain the separator or
+// non-printable bytes make the whole list use --hex-salt.
+func (l *HashList) AddSaltedHash(hash, salt string) error {
+	return l.Add(HashEntry{Hash: hash, Salt: salt})
+}
+
+// Entries returns the entries in the order they were added, which is the order of the
+// lines of the written hash file
+func (l *HashList) Entries() []HashEntry {
+	return append([]HashEntry(nil), l.entries...)
+}
+
+// Len returns the number of entries
+func (l *HashList) Len() int {
+	return len(l.entries)
+}
+
+// HasUsernames reports whether any entry has a username, in which case every line is
+// written with one and the session needs --username
+func (l *HashList) HasUsernames() bool {
+	for _, entry := range l.entries {
+		if entry.Username != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// HexSalt reports whether the salts have to be written in hex, in which case the
+// session needs --hex-salt
+func (l *HashList) HexSalt() bool {
+	for _, entry := range l.entries {
+		if needsHexSalt(entry.Salt, l.separator()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Line returns an entry formatted as a line of the hash file
+func (l *HashList) Line(entry HashEntry) string {
+	return l.formatLine(entry, l.HasUsernames(), l.HexSalt())
+}
+
+// WriteTo writes the list as a hash file, one entry per line
+func (l *HashList) WriteTo(w io.Writer) (int64, error) {
+	usernames := l.HasUsernames()
+	hexSalt := l.HexSalt()
+
+	bw := bufio.NewWriter(w)
+	var written int64
+
+	for _, entry := range l.entries {
+		n, err := bw.WriteString(l.formatLine(entry, usernames, hexSalt) + "\n")
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, bw.Flush()
+}
+
+// WriteFile writes the list to a hash file
+func (l *HashList) WriteFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create hash file: %w", err)
+	}
+
+	if _, err := l.WriteTo(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write hash file: %w", err)
+	}
+
+	return file.Close()
+}
+
+// Apply sets the options a session needs to read the hash file written for the list
+func (l *HashList) Apply(options *CrackOptions) {
+	options.Username = l.HasUsernames()
+	options.HexSalt = l.HexSalt()
+	options.Separator = 0
+	if separator := l.separator(); separator != ':' {
+		options.Separator = separator
+	}
+}
+
+// formatLine formats an entry as username, hash and salt joined by the separator
+func (l *HashList) formatLine(entry HashEntry, usernames, hexSalt bool) string {
+	separator := string(l.separator())
+
+	var sb strings.Builder
+	if usernames {
+		sb.WriteString(entry.Username)
+		sb.WriteString(separator)
+	}
+
+	sb.WriteString(entry.Hash)
+
+	if entry.Salt != "" {
+		sb.WriteString(separator)
+		if hexSalt {
+			sb.WriteString(hex.EncodeToString([]byte(entry.Salt)))
+		} else {
+			sb.WriteString(entry.Salt)
+		}
+	}
+
+	return sb.String()
+}
+
+// separator returns the separator of the list, falling back to the default one
+func (l *HashList) separator() byte {
+	if l.Separator == 0 {
+		return ':'
+	}
+	return l.Separator
+}
+
+// needsHexSalt reports whether a salt cannot be written as is
+func needsHexSalt(salt string, separator byte) bool {
+	for i := 0; i < len(salt); i++ {
+		c := salt[i]
+		if c < 0x20 || c > 0x7e || c == separator {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCrackListSession creates a new CrackSession for cracking the hashes of a list. The
+// list is written to a hash file owned by the session, and --username, --hex-salt and
+// --separator are set to match it.
+func (c *HashcatClient) NewCrackListSession(ctx context.Context, list *HashList, options *CrackOptions) (CrackSession, error) {
+	if list == nil || list.Len() == 0 {
+		return nil, fmt.Errorf("%w: no hashes to crack", ErrInvalidHashFile)
+	}
+
+	// Work on a copy so the caller's options are left untouched
+	sessionOptions := &CrackOptions{HashType: 0, OptimizedKernel: true}
+	if options != nil {
+		copied := *options
+		sessionOptions = &copied
+	}
+	list.Apply(sessionOptions)
+
+	if err := sessionOptions.Validate(); err != nil {
+		return nil, err
+	}
+
+	var content strings.Builder
+	if _, err := list.WriteTo(&content); err != nil {
+		return nil, fmt.Errorf("failed to format hash list: %w", err)
+	}
+
+	if !sessionOptions.skipHashValidation() {
+		err := ValidateHashes(strings.NewReader(content.String()), sessionOptions.HashType, sessionOptions.Username)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sessionName, err := c.newSessionName(sessionOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the hash file next to the session state when the session can be restored
+	hashFile, err := createTempFile(c.sessionDir(sessionOptions), sessionName+"-hashes-*.txt", content.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create hash file: %w", err)
+	}
+
+	session := c.newSession(ctx, sessionName, hashFile, sessionOptions)
+	session.ownsHashFile = true
+
+	return session, nil
+}
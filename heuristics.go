@@ -0,0 +1,179 @@
+package hashcat
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pixelsquared/go-hashcat/models"
+)
+
+// Hash type categories as hashcat names them
+const (
+	categoryRawHash        = "Raw Hash"
+	categoryRawHashSalted  = "Raw Hash salted and/or iterated"
+	categoryOperatingSys   = "Operating System"
+	categoryNetwork        = "Network Protocol"
+	categoryServer         = "HTTP, SMTP, LDAP Server"
+	categoryDatabase       = "Database Server"
+	categoryGenericKDF     = "Generic KDF"
+	categoryFramework      = "Framework"
+	categoryArchive        = "Archive"
+	categoryDocument       = "Document"
+	categoryCryptocurrency = "Cryptocurrency Wallet"
+)
+
+// hashRule recognizes the structure of a hash and lists the modes it may be, most likely first
+type hashRule struct {
+	prefix  string         // Prefix the hash starts with, if any
+	pattern *regexp.Regexp // Pattern the whole hash matches, if any
+	types   []models.HashType
+}
+
+// matches reports whether the hash has the structure recognized by the rule
+func (r hashRule) matches(hash string) bool {
+	if r.prefix != "" && !strings.HasPrefix(hash, r.prefix) {
+		return false
+	}
+	if r.pattern != nil && !r.pattern.MatchString(hash) {
+		return false
+	}
+	return true
+}
+
+// hexPattern returns a pattern matching a hash of n hex digits
+func hexPattern(n int) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`^[0-9a-fA-F]{%d}$`, n))
+}
+
+// hashRules are checked in order; prefixed formats come before bare hex digests so
+// that the most specific rule wins
+var hashRules = []hashRule{
+	// Modular crypt and other prefixed formats
+	{prefix: "$2a$", types: []models.HashType{{ID: 3200, Name: "bcrypt $2*$, Blowfish (Unix)", Category: categoryOperatingSys}}},
+	{prefix: "$2b$", types: []models.HashType{{ID: 3200, Name: "bcrypt $2*$, Blowfish (Unix)", Category: categoryOperatingSys}}},
+	{prefix: "$2y$", types: []models.HashType{{ID: 3200, Name: "bcrypt $2*$, Blowfish (Unix)", Category: categoryOperatingSys}}},
+	{prefix: "$6$", types: []models.HashType{{ID: 1800, Name: "sha512crypt $6$, SHA512 (Unix)", Category: categoryOperatingSys}}},
+	{prefix: "$5$", types: []models.HashType{{ID: 7400, Name: "sha256crypt $5$, SHA256 (Unix)", Category: categoryOperatingSys}}},
+	{prefix: "$1$", types: []models.HashType{{ID: 500, Name: "md5crypt, MD5 (Unix), Cisco-IOS $1$ (MD5)", Category: categoryOperatingSys}}},
+	{prefix: "$apr1$", types: []models.HashType{{ID: 1600, Name: "Apache $apr1$ MD5, md5apr1, MD5 (APR)", Category: categoryServer}}},
+	{prefix: "$P$", types: []models.HashType{{ID: 400, Name: "phpass", Category: categoryGenericKDF}}},
+	{prefix: "$H$", types: []models.HashType{{ID: 400, Name: "phpass", Category: categoryGenericKDF}}},
+	{prefix: "$krb5tgs$23$", types: []models.HashType{{ID: 13100, Name: "Kerberos 5, etype 23, TGS-REP", Category: categoryNetwork}}},
+	{prefix: "$krb5tgs$17$", types: []models.HashType{{ID: 19600, Name: "Kerberos 5, etype 17, TGS-REP", Category: categoryNetwork}}},
+	{prefix: "$krb5tgs$18$", types: []models.HashType{{ID: 19700, Name: "Kerberos 5, etype 18, TGS-REP", Category: categoryNetwork}}},
+	{prefix: "$krb5asrep$23$", types: []models.HashType{{ID: 18200, Name: "Kerberos 5, etype 23, AS-REP", Category: categoryNetwork}}},
+	{prefix: "$krb5pa$23$", types: []models.HashType{{ID: 7500, Name: "Kerberos 5, etype 23, AS-REQ Pre-Auth", Category: categoryNetwork}}},
+	{prefix: "$DCC2$", types: []models.HashType{{ID: 2100, Name: "Domain Cached Credentials 2 (DCC2), MS Cache 2", Category: categoryOperatingSys}}},
+	{prefix: "WPA*", types: []models.HashType{{ID: 22000, Name: "WPA-PBKDF2-PMKID+EAPOL", Category: categoryNetwork}}},
+	{prefix: "{SSHA}", types: []models.HashType{{ID: 111, Name: "nsldaps, SSHA-1(Base64), Netscape LDAP SSHA", Category: categoryServer}}},
+	{prefix: "{SHA}", types: []models.HashType{{ID: 101, Name: "nsldap, SHA-1(Base64), Netscape LDAP SHA", Category: categoryServer}}},
+	{prefix: "pbkdf2_sha256$", types: []models.HashType{{ID: 10000, Name: "Django (PBKDF2-SHA256)", Category: categoryFramework}}},
+	{prefix: "$zip2$", types: []models.HashType{{ID: 13600, Name: "WinZip", Category: categoryArchive}}},
+	{prefix: "$7z$", types: []models.HashType{{ID: 11600, Name: "7-Zip", Category: categoryArchive}}},
+	{prefix: "$bitcoin$", types: []models.HashType{{ID: 11300, Name: "Bitcoin/Litecoin wallet.dat", Category: categoryCryptocurrency}}},
+	{prefix: "$office$*2007*", types: []models.HashType{{ID: 9400, Name: "MS Office 2007", Category: categoryDocument}}},
+	{prefix: "$office$*2010*", types: []models.HashType{{ID: 9500, Name: "MS Office 2010", Category: categoryDocument}}},
+	{prefix: "$office$*2013*", types: []models.HashType{{ID: 9600, Name: "MS Office 2013", Category: categoryDocument}}},
+	{pattern: regexp.MustCompile(`^\*[0-9A-Fa-f]{40}$`), types: []models.HashType{{ID: 300, Name: "MySQL4.1/MySQL5", Category: categoryDatabase}}},
+
+	// Challenge-response captures: user::domain:challenge:hmac:blob and user::domain:lm:nt:challenge
+	{pattern: regexp.MustCompile(`^[^:]*::[^:]*:[0-9a-fA-F]{16}:[0-9a-fA-F]{32}:[0-9a-fA-F]+$`), types: []models.HashType{{ID: 5600, Name: "NetNTLMv2", Category: categoryNetwork}}},
+	{pattern: regexp.MustCompile(`^[^:]*::[^:]*:[0-9a-fA-F]{48}:[0-9a-fA-F]{48}:[0-9a-fA-F]{16}$`), types: []models.HashType{{ID: 5500, Name: "NetNTLMv1 / NetNTLMv1+ESS", Category: categoryNetwork}}},
+
+	// Salted hex digests
+	{pattern: regexp.MustCompile(`^[0-9a-fA-F]{32}:.+$`), types: []models.HashType{
+		{ID: 10, Name: "md5($pass.$salt)", Category: categoryRawHashSalted},
+		{ID: 20, Name: "md5($salt.$pass)", Category: categoryRawHashSalted},
+	}},
+	{pattern: regexp.MustCompile(`^[0-9a-fA-F]{40}:.+$`), types: []models.HashType{
+		{ID: 110, Name: "sha1($pass.$salt)", Category: categoryRawHashSalted},
+		{ID: 120, Name: "sha1($salt.$pass)", Category: categoryRawHashSalted},
+	}},
+
+	// Bare hex digests, told apart only by their length
+	{pattern: hexPattern(16), types: []models.HashType{
+		{ID: 3000, Name: "LM", Category: categoryOperatingSys},
+		{ID: 200, Name: "MySQL323", Category: categoryDatabase},
+	}},
+	{pattern: hexPattern(32), types: []models.HashType{
+		{ID: 0, Name: "MD5", Category: categoryRawHash},
+		{ID: 1000, Name: "NTLM", Category: categoryOperatingSys},
+		{ID: 900, Name: "MD4", Category: categoryRawHash},
+	}},
+	{pattern: hexPattern(40), types: []models.HashType{
+		{ID: 100, Name: "SHA1", Category: categoryRawHash},
+		{ID: 6000, Name: "RIPEMD-160", Category: categoryRawHash},
+	}},
+	{pattern: hexPattern(56), types: []models.HashType{
+		{ID: 1300, Name: "SHA2-224", Category: categoryRawHash},
+		{ID: 17300, Name: "SHA3-224", Category: categoryRawHash},
+	}},
+	{pattern: hexPattern(64), types: []models.HashType{
+		{ID: 1400, Name: "SHA2-256", Category: categoryRawHash},
+		{ID: 17400, Name: "SHA3-256", Category: categoryRawHash},
+		{ID: 17800, Name: "Keccak-256", Category: categoryRawHash},
+	}},
+	{pattern: hexPattern(96), types: []models.HashType{
+		{ID: 10800, Name: "SHA2-384", Category: categoryRawHash},
+		{ID: 17500, Name: "SHA3-384", Category: categoryRawHash},
+	}},
+	{pattern: hexPattern(128), types: []models.HashType{
+		{ID: 1700, Name: "SHA2-512", Category: categoryRawHash},
+		{ID: 17600, Name: "SHA3-512", Category: categoryRawHash},
+		{ID: 6100, Name: "Whirlpool", Category: categoryRawHash},
+	}},
+}
+
+// IdentifyHash guesses the hash types of a hash from its length, charset and prefix,
+// without running hashcat. The most likely hash type comes first; nil is returned
+// when the structure of the hash is not recognized.
+func IdentifyHash(hash string) []models.HashType {
+	hash = strings.TrimSpace(hash)
+
+	for _, rule := range hashRules {
+		if rule.matches(hash) {
+			return append([]models.HashType(nil), rule.types...)
+		}
+	}
+
+	return nil
+}
+
+// IdentifyHashes guesses the hash types that all of the given hashes may be,
+// in the order IdentifyHash ranks them for the first hash
+func IdentifyHashes(hashes []string) []models.HashType {
+	var candidates []models.HashType
+	first := true
+
+	for _, hash := range hashes {
+		if strings.TrimSpace(hash) == "" {
+			continue
+		}
+
+		types := IdentifyHash(hash)
+		if first {
+			candidates = types
+			first = false
+			continue
+		}
+
+		// Keep only the hash types every hash may be
+		kept := candidates[:0]
+		for _, candidate := range candidates {
+			for _, hashType := range types {
+				if hashType.ID == candidate.ID {
+					kept = append(kept, candidate)
+					break
+				}
+			}
+		}
+		candidates = kept
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	return candidates
+}
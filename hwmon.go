@@ -0,0 +1,328 @@
+package hashcat
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pixelsquared/go-hashcat/models"
+)
+
+// Defaults of a HardwareMonitor
+const (
+	defaultSampleInterval  = 5 * time.Second
+	defaultTelemetryLength = 720
+)
+
+// hardwareStatusRuntime is how long GetHardwareStatus keeps the devices busy, in seconds,
+// so that their utilization and temperature reflect a running kernel
+const hardwareStatusRuntime = 3
+
+// TelemetrySource reads the current hardware state of the devices
+type TelemetrySource interface {
+	// Sample returns a snapshot of each device the source knows about. It may return
+	// no telemetry when none is available yet.
+	Sample(ctx context.Context) ([]models.DeviceTelemetry, error)
+}
+
+// TelemetrySourceFunc adapts a function to a TelemetrySource, for instance to read
+// vendor tools or a metrics endpoint
+type TelemetrySourceFunc func(ctx context.Context) ([]models.DeviceTelemetry, error)
+
+// Sample calls the function
+func (f TelemetrySourceFunc) Sample(ctx context.Context) ([]models.DeviceTelemetry, error) {
+	return f(ctx)
+}
+
+// SessionTelemetry returns a source reading the device status of the latest progress
+// update of a session. The status JSON only reports temperature and utilization.
+func SessionTelemetry(session CrackSession) TelemetrySource {
+	return TelemetrySourceFunc(func(ctx context.Context) ([]models.DeviceTelemetry, error) {
+		progress := session.LatestProgress()
+		if progress == nil {
+			return nil, nil
+		}
+
+		now := time.Now()
+		telemetry := make([]models.DeviceTelemetry, len(progress.Devices))
+		for i, device := range progress.Devices {
+			telemetry[i] = models.TelemetryFromStatus(device, now)
+		}
+
+		return telemetry, nil
+	})
+}
+
+// CommandTelemetry returns a source running a command, such as a vendor tool or a script
+// printing hashcat's status screen, and parsing its output. ParseHwmonOutput parses the
+// Hardware.Mon lines hashcat prints.
+func CommandTelemetry(name string, args []string, parse func(output string, at time.Time) []models.DeviceTelemetry) TelemetrySource {
+	return TelemetrySourceFunc(func(ctx context.Context) ([]models.DeviceTelemetry, error) {
+		cmd := exec.CommandContext(ctx, name, args...)
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrExecutionFailed, name, err)
+		}
+
+		return parse(string(output), time.Now()), nil
+	})
+}
+
+// GetHardwareStatus returns the temperature, fan speed and utilization of each device
+// outside of a crack session. Hashcat only reads its hardware monitor while it runs an
+// attack, so it is run for a few seconds against a hash it cannot crack and the readings
+// of its last status screen are returned. GetHardwareStatus can be used as a
+// TelemetrySource through TelemetrySourceFunc.
+func (c *HashcatClient) GetHardwareStatus(ctx context.Context) ([]models.DeviceTelemetry, error) {
+	args := []string{
+		"--hash-type=0",
+		"--attack-mode=3",
+		fmt.Sprintf("--session=hwmon-%d", time.Now().UnixNano()),
+		"--runtime=" + strconv.Itoa(hardwareStatusRuntime),
+		"--status",
+		"--status-timer=1",
+		"--potfile-disable",
+		"--restore-disable",
+		"--logfile-disable",
+		"00000000000000000000000000000000",
+		"?b?b?b?b?b?b?b?b?b?b",
+	}
+
+	cmd := exec.CommandContext(ctx, c.config.BinaryPath, args...)
+	output, err := cmd.CombinedOutput()
+
+	// Reaching the runtime is the expected way for hashcat to stop
+	if err != nil {
+		var messages outputClassifier
+		messages.addOutput(string(output))
+		err = classifyExit("hardware status", err, &messages)
+		if !IsStopOutcome(err) || ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to get hardware status: %w", err)
+		}
+	}
+
+	// Keep the last reading of each device
+	var telemetry []models.DeviceTelemetry
+	latest := make(map[int]int)
+	for _, sample := range ParseHwmonOutput(string(output), time.Now()) {
+		if i, ok := latest[sample.DeviceID]; ok {
+			telemetry[i] = sample
+			continue
+		}
+		latest[sample.DeviceID] = len(telemetry)
+		telemetry = append(telemetry, sample)
+	}
+
+	if len(telemetry) == 0 {
+		return nil, fmt.Errorf("failed to get hardware status: %w", ErrNoDevices)
+	}
+
+	return telemetry, nil
+}
+
+// Patterns for the hardware monitor lines of hashcat's status screen:
+//
+//	Hardware.Mon.#1..: Temp: 65c Fan: 45% Util:100% Core:1965MHz Mem:9251MHz Bus:16
+var (
+	hwmonLineRe  = regexp.MustCompile(`^\s*Hardware\.Mon\.#(\d+)\.*:\s*(.*)$`)
+	hwmonTempRe  = regexp.MustCompile(`Temp:\s*(-?\d+)\s*c`)
+	hwmonFanRe   = regexp.MustCompile(`Fan:\s*(-?\d+)\s*%`)
+	hwmonUtilRe  = regexp.MustCompile(`Util:\s*(-?\d+)\s*%`)
+	hwmonPowerRe = regexp.MustCompile(`(?:Power|Pwr):\s*(\d+)\s*W`)
+	hwmonCoreRe  = regexp.MustCompile(`Core:\s*(\d+)\s*MHz`)
+	hwmonMemRe   = regexp.MustCompile(`Mem:\s*(\d+)\s*MHz`)
+	hwmonBusRe   = regexp.MustCompile(`Bus:\s*(\d+)`)
+)
+
+// ParseHwmonOutput parses the Hardware.Mon lines of hashcat's status screen into a
+// snapshot of each device, taken at the given time. Devices hashcat reports as N/A
+// are returned with every reading unavailable.
+func ParseHwmonOutput(output string, at time.Time) []models.DeviceTelemetry {
+	var telemetry []models.DeviceTelemetry
+
+	for _, line := range strings.Split(output, "\n") {
+		match := hwmonLineRe.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if match == nil {
+			continue
+		}
+
+		id, _ := strconv.Atoi(match[1])
+		readings := match[2]
+		sample := models.NewDeviceTelemetry(id, at)
+
+		for _, field := range []struct {
+			pattern *regexp.Regexp
+			value   *int
+		}{
+			{hwmonTempRe, &sample.Temperature},
+			{hwmonFanRe, &sample.FanSpeed},
+			{hwmonUtilRe, &sample.Utilization},
+			{hwmonPowerRe, &sample.PowerWatts},
+			{hwmonCoreRe, &sample.CoreClockMHz},
+			{hwmonMemRe, &sample.MemoryClockMHz},
+			{hwmonBusRe, &sample.BusLanes},
+		} {
+			if m := field.pattern.FindStringSubmatch(readings); m != nil {
+				*field.value, _ = strconv.Atoi(m[1])
+			}
+		}
+
+		telemetry = append(telemetry, sample)
+	}
+
+	return telemetry
+}
+
+// HardwareMonitorOptions configures a HardwareMonitor
+type HardwareMonitorOptions struct {
+	Interval time.Duration // Time between samples (0=5s)
+	History  int           // Samples kept per device, oldest dropped first (0=720)
+
+	// OnSample is called with each snapshot after it has been recorded
+	OnSample func([]models.DeviceTelemetry)
+
+	// OnError is called when the source fails to sample; sampling continues
+	OnError func(error)
+}
+
+// HardwareMonitor samples device telemetry from a source at a fixed interval and keeps
+// a time series for each device
+type HardwareMonitor struct {
+	source  TelemetrySource
+	options HardwareMonitorOptions
+
+	mutex  sync.Mutex
+	series map[int]*models.TelemetrySeries
+}
+
+// NewHardwareMonitor creates a monitor for the source. Options may be nil.
+func NewHardwareMonitor(source TelemetrySource, options *HardwareMonitorOptions) *HardwareMonitor {
+	monitor := &HardwareMonitor{
+		source: source,
+		series: make(map[int]*models.TelemetrySeries),
+	}
+
+	if options != nil {
+		monitor.options = *options
+	}
+	if monitor.options.Interval <= 0 {
+		monitor.options.Interval = defaultSampleInterval
+	}
+	if monitor.options.History <= 0 {
+		monitor.options.History = defaultTelemetryLength
+	}
+
+	return monitor
+}
+
+// Run samples the source until ctx is done. Sampling errors are reported to OnError.
+func (m *HardwareMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := m.SampleNow(ctx); err != nil && m.options.OnError != nil && ctx.Err() == nil {
+			m.options.OnError(err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// SampleNow samples the source once and records the snapshot
+func (m *HardwareMonitor) SampleNow(ctx context.Context) ([]models.DeviceTelemetry, error) {
+	telemetry, err := m.source.Sample(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(telemetry) == 0 {
+		return nil, nil
+	}
+
+	m.record(telemetry)
+
+	if m.options.OnSample != nil {
+		m.options.OnSample(telemetry)
+	}
+
+	return telemetry, nil
+}
+
+// record appends a snapshot to the series of each device
+func (m *HardwareMonitor) record(telemetry []models.DeviceTelemetry) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, sample := range telemetry {
+		series, ok := m.series[sample.DeviceID]
+		if !ok {
+			series = &models.TelemetrySeries{DeviceID: sample.DeviceID}
+			m.series[sample.DeviceID] = series
+		}
+
+		series.Samples = append(series.Samples, sample)
+		if excess := len(series.Samples) - m.options.History; excess > 0 {
+			series.Samples = append(series.Samples[:0], series.Samples[excess:]...)
+		}
+	}
+}
+
+// Series returns a copy of the time series of a device
+func (m *HardwareMonitor) Series(deviceID int) (*models.TelemetrySeries, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	series, ok := m.series[deviceID]
+	if !ok {
+		return nil, false
+	}
+
+	return &models.TelemetrySeries{
+		DeviceID: series.DeviceID,
+		Samples:  append([]models.DeviceTelemetry(nil), series.Samples...),
+	}, true
+}
+
+// Latest returns the most recent sample of each device, ordered by device ID
+func (m *HardwareMonitor) Latest() []models.DeviceTelemetry {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	latest := make([]models.DeviceTelemetry, 0, len(m.series))
+	for _, series := range m.series {
+		if sample, ok := series.Latest(); ok {
+			latest = append(latest, sample)
+		}
+	}
+
+	sort.Slice(latest, func(i, j int) bool {
+		return latest[i].DeviceID < latest[j].DeviceID
+	})
+
+	return latest
+}
+
+// DeviceIDs returns the IDs of the devices that have been sampled, in ascending order
+func (m *HardwareMonitor) DeviceIDs() []int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	ids := make([]int, 0, len(m.series))
+	for id := range m.series {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	return ids
+}
@@ -0,0 +1,122 @@
+package hashcat
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pixelsquared/go-hashcat/models"
+)
+
+// identifyRowRegex matches a row of the table printed by hashcat --identify:
+//
+//	0 | MD5                                    | Raw Hash
+var identifyRowRegex = regexp.MustCompile(`^\s*(\d+)\s*\|\s*(.+?)\s*\|\s*(.+?)\s*$`)
+
+// Identify returns the hash types that match the given hashes, most likely first.
+// It runs hashcat --identify and ranks the modes it reports using the same heuristics
+// as IdentifyHash. When the hashcat binary is unavailable, the heuristics are used alone.
+func (c *HashcatClient) Identify(ctx context.Context, hashes []string) ([]models.HashType, error) {
+	if len(hashes) == 0 {
+		return nil, ErrInvalidHash
+	}
+
+	heuristic := IdentifyHashes(hashes)
+
+	// hashcat identifies the hashes of a file when given a path instead of a hash
+	hashFile, err := createTempFile("", "identify-*.txt", strings.Join(hashes, "\n")+"\n")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create hash file: %w", err)
+	}
+	defer os.Remove(hashFile)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.config.BinaryPath, "--identify", "--quiet", hashFile)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	runErr := cmd.Run()
+	if runErr != nil && binaryUnavailable(runErr) {
+		if len(heuristic) == 0 {
+			return nil, ErrUnknownHashType
+		}
+		return heuristic, nil
+	}
+
+	candidates := ParseIdentifyOutput(stdout.String())
+	if len(candidates) == 0 {
+		if runErr != nil && !strings.Contains(stdout.String()+stderr.String(), "No hash-mode match") {
+			return nil, NewHashcatError("identify", runErr, strings.TrimSpace(stderr.String()))
+		}
+		return nil, ErrUnknownHashType
+	}
+
+	return rankHashTypes(candidates, heuristic), nil
+}
+
+// ParseIdentifyOutput parses the hash-mode table printed by hashcat --identify,
+// keeping the modes in the order hashcat lists them
+func ParseIdentifyOutput(output string) []models.HashType {
+	var hashTypes []models.HashType
+
+	for _, line := range strings.Split(output, "\n") {
+		matches := identifyRowRegex.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if matches == nil {
+			continue
+		}
+
+		id, err := strconv.Atoi(matches[1])
+		if err != nil {
+			continue
+		}
+
+		hashTypes = append(hashTypes, models.HashType{
+			ID:       id,
+			Name:     matches[2],
+			Category: matches[3],
+		})
+	}
+
+	return hashTypes
+}
+
+// rankHashTypes orders the modes reported by hashcat so that those the heuristics
+// consider likely come first, in the heuristics' order, followed by the others
+func rankHashTypes(candidates, heuristic []models.HashType) []models.HashType {
+	rank := make(map[int]int, len(heuristic))
+	for i, hashType := range heuristic {
+		rank[hashType.ID] = i
+	}
+
+	ranked := make([]models.HashType, 0, len(candidates))
+	var rest []models.HashType
+
+	for _, candidate := range candidates {
+		if _, ok := rank[candidate.ID]; ok {
+			ranked = append(ranked, candidate)
+		} else {
+			rest = append(rest, candidate)
+		}
+	}
+
+	// Insertion sort keeps the order stable for the few modes involved
+	for i := 1; i < len(ranked); i++ {
+		for j := i; j > 0 && rank[ranked[j].ID] < rank[ranked[j-1].ID]; j-- {
+			ranked[j], ranked[j-1] = ranked[j-1], ranked[j]
+		}
+	}
+
+	return append(ranked, rest...)
+}
+
+// binaryUnavailable reports whether a command failed because the binary could not be run
+func binaryUnavailable(err error) bool {
+	return errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission)
+}
@@ -0,0 +1,182 @@
+package mask
+
+import (
+	"sort"
+)
+
+// Charset is the sorted set of bytes a single mask position can take
+type Charset []byte
+
+// Built-in charsets as defined by hashcat
+var (
+	CharsetLower   = newCharset([]byte("abcdefghijklmnopqrstuvwxyz"))
+	CharsetUpper   = newCharset([]byte("ABCDEFGHIJKLMNOPQRSTUVWXYZ"))
+	CharsetDigits  = newCharset([]byte("0123456789"))
+	CharsetHexLow  = newCharset([]byte("0123456789abcdef"))
+	CharsetHexUp   = newCharset([]byte("0123456789ABCDEF"))
+	CharsetSpecial = newCharset([]byte(" !\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"))
+	CharsetAll     = CharsetLower.Union(CharsetUpper).Union(CharsetDigits).Union(CharsetSpecial)
+	CharsetBinary  = allBytes()
+)
+
+// builtinCharsets maps the built-in charset symbols to their charsets
+var builtinCharsets = map[byte]Charset{
+	'l': CharsetLower,
+	'u': CharsetUpper,
+	'd': CharsetDigits,
+	'h': CharsetHexLow,
+	'H': CharsetHexUp,
+	's': CharsetSpecial,
+	'a': CharsetAll,
+	'b': CharsetBinary,
+}
+
+// BuiltinCharset returns the built-in charset for the given symbol (e.g. 'l' for ?l)
+func BuiltinCharset(symbol byte) (Charset, bool) {
+	charset, ok := builtinCharsets[symbol]
+	return charset, ok
+}
+
+// Size returns the number of distinct bytes in the charset
+func (c Charset) Size() int {
+	return len(c)
+}
+
+// Contains reports whether the charset contains the given byte
+func (c Charset) Contains(b byte) bool {
+	i := sort.Search(len(c), func(i int) bool { return c[i] >= b })
+	return i < len(c) && c[i] == b
+}
+
+// Union returns a new charset containing the bytes of both charsets
+func (c Charset) Union(other Charset) Charset {
+	merged := make([]byte, 0, len(c)+len(other))
+	merged = append(merged, c...)
+	merged = append(merged, other...)
+	return newCharset(merged)
+}
+
+// String returns the bytes of the charset as a string
+func (c Charset) String() string {
+	return string(c)
+}
+
+// newCharset sorts and deduplicates the given bytes into a Charset
+func newCharset(b []byte) Charset {
+	var seen [256]bool
+	for _, c := range b {
+		seen[c] = true
+	}
+
+	charset := make(Charset, 0, len(b))
+	for i, ok := range seen {
+		if ok {
+			charset = append(charset, byte(i))
+		}
+	}
+	return charset
+}
+
+// allBytes returns the charset containing every byte value
+func allBytes() Charset {
+	charset := make(Charset, 256)
+	for i := range charset {
+		charset[i] = byte(i)
+	}
+	return charset
+}
+
+// ParseCharset parses a custom charset definition such as "?l?d_" into a Charset.
+// Definitions may reference built-in charsets and the custom charsets given in defined;
+// a nil entry in defined means that custom charset is not available. When hex is true
+// the literal part of the definition is read as pairs of hex digits.
+func ParseCharset(definition string, hex bool, defined [4]Charset) (Charset, error) {
+	var b []byte
+
+	for i := 0; i < len(definition); i++ {
+		c := definition[i]
+
+		if c == '?' {
+			if i+1 >= len(definition) {
+				return nil, &ParseError{Input: definition, Pos: i, Err: ErrIncompletePlaceholder}
+			}
+
+			symbol := definition[i+1]
+			charset, err := resolveSymbol(symbol, defined)
+			if err != nil {
+				return nil, &ParseError{Input: definition, Pos: i, Err: err}
+			}
+
+			b = append(b, charset...)
+			i++
+			continue
+		}
+
+		if hex {
+			value, err := parseHexByte(definition, i)
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, value)
+			i++
+			continue
+		}
+
+		b = append(b, c)
+	}
+
+	if len(b) == 0 {
+		return nil, &ParseError{Input: definition, Pos: 0, Err: ErrEmptyCharset}
+	}
+
+	return newCharset(b), nil
+}
+
+// resolveSymbol returns the charset for the symbol following a '?'
+func resolveSymbol(symbol byte, custom [4]Charset) (Charset, error) {
+	if symbol == '?' {
+		return Charset{'?'}, nil
+	}
+
+	if symbol >= '1' && symbol <= '4' {
+		charset := custom[symbol-'1']
+		if charset == nil {
+			return nil, ErrUndefinedCharset
+		}
+		return charset, nil
+	}
+
+	if charset, ok := builtinCharsets[symbol]; ok {
+		return charset, nil
+	}
+
+	return nil, ErrUnknownCharset
+}
+
+// parseHexByte decodes the two hex digits starting at pos
+func parseHexByte(s string, pos int) (byte, error) {
+	if pos+1 >= len(s) {
+		return 0, &ParseError{Input: s, Pos: pos, Err: ErrInvalidHex}
+	}
+
+	hi, ok1 := hexValue(s[pos])
+	lo, ok2 := hexValue(s[pos+1])
+	if !ok1 || !ok2 {
+		return 0, &ParseError{Input: s, Pos: pos, Err: ErrInvalidHex}
+	}
+
+	return hi<<4 | lo, nil
+}
+
+// hexValue returns the value of a single hex digit
+func hexValue(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
@@ -0,0 +1,225 @@
+package mask
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// ErrInvalidMaskFile is returned when a .hcmask file cannot be read or written
+var ErrInvalidMaskFile = errors.New("invalid mask file")
+
+// Entry is a single line of a .hcmask file: a mask with its own custom charsets
+type Entry struct {
+	CustomCharsets [4]string // Definitions of ?1 to ?4 for this mask only
+	Mask           string    // The mask itself
+}
+
+// Config returns the parse configuration for the entry
+func (e Entry) Config(hexCharset bool) Config {
+	return Config{CustomCharsets: e.CustomCharsets, HexCharset: hexCharset}
+}
+
+// Parse parses the entry's mask with its custom charsets
+func (e Entry) Parse(hexCharset bool) (*Mask, error) {
+	return ParseWith(e.Mask, e.Config(hexCharset))
+}
+
+// String returns the entry as a line of a .hcmask file
+func (e Entry) String() string {
+	// Custom charsets are positional, so empty ones are kept up to the last defined charset
+	count := 0
+	for i, charset := range e.CustomCharsets {
+		if charset != "" {
+			count = i + 1
+		}
+	}
+
+	fields := make([]string, 0, count+1)
+	for _, charset := range e.CustomCharsets[:count] {
+		fields = append(fields, escapeField(charset))
+	}
+	fields = append(fields, escapeField(e.Mask))
+
+	return strings.Join(fields, ",")
+}
+
+// LineError reports an invalid line of a .hcmask file
+type LineError struct {
+	Line int   // 1-based line number
+	Err  error // The underlying error
+}
+
+// Error implements the error interface
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap implements the error unwrapping interface
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// List is an ordered sequence of masks that hashcat runs one after another
+type List []Entry
+
+// ReadList reads a list of masks in .hcmask format.
+// Empty lines and lines starting with '#' are skipped; "\," is a literal comma.
+func ReadList(r io.Reader) (List, error) {
+	var list List
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := splitFields(line)
+		if len(fields) > 5 {
+			return nil, &LineError{Line: lineNumber, Err: fmt.Errorf("%w: more than 4 custom charsets", ErrInvalidMaskFile)}
+		}
+
+		entry := Entry{Mask: fields[len(fields)-1]}
+		copy(entry.CustomCharsets[:], fields[:len(fields)-1])
+
+		if entry.Mask == "" {
+			return nil, &LineError{Line: lineNumber, Err: ErrEmptyMask}
+		}
+
+		list = append(list, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mask file: %w", err)
+	}
+
+	return list, nil
+}
+
+// ReadListFile reads a .hcmask file
+func ReadListFile(path string) (List, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mask file: %w", err)
+	}
+	defer file.Close()
+
+	return ReadList(file)
+}
+
+// WriteTo writes the list in .hcmask format
+func (l List) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+
+	for i, entry := range l {
+		if strings.HasPrefix(entry.String(), "#") {
+			return written, &LineError{Line: i + 1, Err: fmt.Errorf("%w: entry would be read as a comment", ErrInvalidMaskFile)}
+		}
+
+		n, err := io.WriteString(w, entry.String()+"\n")
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// WriteFile writes the list to a .hcmask file
+func (l List) WriteFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create mask file: %w", err)
+	}
+
+	if _, err := l.WriteTo(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Validate parses every entry of the list
+func (l List) Validate(hexCharset bool) error {
+	if len(l) == 0 {
+		return ErrEmptyMask
+	}
+
+	for i, entry := range l {
+		if _, err := entry.Parse(hexCharset); err != nil {
+			return &LineError{Line: i + 1, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// Keyspaces returns the keyspace of each entry of the list
+func (l List) Keyspaces(hexCharset bool) ([]*big.Int, error) {
+	keyspaces := make([]*big.Int, 0, len(l))
+
+	for i, entry := range l {
+		m, err := entry.Parse(hexCharset)
+		if err != nil {
+			return nil, &LineError{Line: i + 1, Err: err}
+		}
+		keyspaces = append(keyspaces, m.Keyspace())
+	}
+
+	return keyspaces, nil
+}
+
+// Keyspace returns the total keyspace of the list
+func (l List) Keyspace(hexCharset bool) (*big.Int, error) {
+	keyspaces, err := l.Keyspaces(hexCharset)
+	if err != nil {
+		return nil, err
+	}
+
+	total := new(big.Int)
+	for _, keyspace := range keyspaces {
+		total.Add(total, keyspace)
+	}
+
+	return total, nil
+}
+
+// splitFields splits a .hcmask line on unescaped commas and removes the escapes
+func splitFields(line string) []string {
+	var fields []string
+	var current strings.Builder
+
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' && i+1 < len(line) && line[i+1] == ',' {
+			current.WriteByte(',')
+			i++
+			continue
+		}
+
+		if line[i] == ',' {
+			fields = append(fields, current.String())
+			current.Reset()
+			continue
+		}
+
+		current.WriteByte(line[i])
+	}
+
+	return append(fields, current.String())
+}
+
+// escapeField escapes the commas of a charset or mask for a .hcmask line
+func escapeField(field string) string {
+	return strings.ReplaceAll(field, ",", "\\,")
+}
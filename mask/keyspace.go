@@ -0,0 +1,67 @@
+package mask
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// LengthKeyspace is the keyspace of a mask truncated to a given length
+type LengthKeyspace struct {
+	Length   int      // Candidate length
+	Keyspace *big.Int // Number of candidates of that length
+}
+
+// Keyspace returns the number of candidates generated by the full mask
+func (m *Mask) Keyspace() *big.Int {
+	keyspace, _ := m.KeyspaceAt(m.Len())
+	return keyspace
+}
+
+// KeyspaceAt returns the number of candidates generated by the first length positions
+// of the mask, which is what hashcat runs for that length in increment mode
+func (m *Mask) KeyspaceAt(length int) (*big.Int, error) {
+	if length < 0 || length > m.Len() {
+		return nil, fmt.Errorf("%w: length %d outside mask length %d", ErrInvalidIncrement, length, m.Len())
+	}
+
+	keyspace := big.NewInt(1)
+	for _, position := range m.Positions[:length] {
+		keyspace.Mul(keyspace, big.NewInt(int64(position.Charset.Size())))
+	}
+
+	return keyspace, nil
+}
+
+// IncrementKeyspace returns the keyspace for every length from min to max inclusive,
+// as hashcat runs them with --increment --increment-min=min --increment-max=max
+func (m *Mask) IncrementKeyspace(min, max int) ([]LengthKeyspace, error) {
+	if min < 1 || max < min || max > m.Len() {
+		return nil, fmt.Errorf("%w: %d-%d for mask length %d", ErrInvalidIncrement, min, max, m.Len())
+	}
+
+	keyspaces := make([]LengthKeyspace, 0, max-min+1)
+	for length := min; length <= max; length++ {
+		keyspace, err := m.KeyspaceAt(length)
+		if err != nil {
+			return nil, err
+		}
+		keyspaces = append(keyspaces, LengthKeyspace{Length: length, Keyspace: keyspace})
+	}
+
+	return keyspaces, nil
+}
+
+// TotalKeyspace returns the sum of the keyspaces for every length from min to max inclusive
+func (m *Mask) TotalKeyspace(min, max int) (*big.Int, error) {
+	keyspaces, err := m.IncrementKeyspace(min, max)
+	if err != nil {
+		return nil, err
+	}
+
+	total := new(big.Int)
+	for _, lengthKeyspace := range keyspaces {
+		total.Add(total, lengthKeyspace.Keyspace)
+	}
+
+	return total, nil
+}
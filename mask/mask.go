@@ -0,0 +1,227 @@
+// Package mask parses and validates hashcat masks and computes their keyspace.
+//
+// A mask describes the candidates of a brute-force attack one position at a time.
+// Each position is either a literal byte or a charset placeholder:
+//
+//	?l  abcdefghijklmnopqrstuvwxyz
+//	?u  ABCDEFGHIJKLMNOPQRSTUVWXYZ
+//	?d  0123456789
+//	?h  0123456789abcdef
+//	?H  0123456789ABCDEF
+//	?s  «space»!"#$%&'()*+,-./:;<=>?@[\]^_`{|}~
+//	?a  ?l?u?d?s
+//	?b  0x00 - 0xff
+//	?1  custom charset 1 (also ?2, ?3 and ?4)
+//	??  a literal '?'
+//
+// Parsing is done in pure Go so masks can be checked and sized before hashcat is started:
+//
+//	m, err := mask.ParseWith("?1?l?l?l?d?d", mask.Config{
+//	    CustomCharsets: [4]string{"?u?l"},
+//	})
+//	if err != nil {
+//	    log.Fatalf("invalid mask: %v", err)
+//	}
+//	fmt.Println(m.Len(), m.Keyspace()) // 6 91395200
+package mask
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors reported while parsing masks and charsets
+var (
+	ErrEmptyMask             = errors.New("empty mask")
+	ErrEmptyCharset          = errors.New("empty charset")
+	ErrIncompletePlaceholder = errors.New("incomplete charset placeholder")
+	ErrUnknownCharset        = errors.New("unknown charset")
+	ErrUndefinedCharset      = errors.New("custom charset is not defined")
+	ErrInvalidHex            = errors.New("invalid hex value")
+	ErrInvalidIncrement      = errors.New("invalid increment range")
+)
+
+// ParseError describes where in a mask or charset parsing failed
+type ParseError struct {
+	Input string // The mask or charset being parsed
+	Pos   int    // Byte offset of the offending placeholder or literal
+	Err   error  // The underlying error
+}
+
+// Error implements the error interface
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("mask %q: %v at position %d", e.Input, e.Err, e.Pos)
+}
+
+// Unwrap implements the error unwrapping interface
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Config holds the settings that change how a mask is interpreted
+type Config struct {
+	CustomCharsets [4]string // Definitions of the custom charsets ?1 to ?4 (-1 to -4)
+	HexCharset     bool      // Charsets and literals are given in hex (--hex-charset)
+}
+
+// PositionKind identifies what a mask position is made of
+type PositionKind int
+
+const (
+	PositionLiteral PositionKind = iota // A literal byte, including ??
+	PositionBuiltin                     // A built-in charset such as ?l
+	PositionCustom                      // A custom charset ?1 to ?4
+)
+
+// Position is a single candidate position of a mask
+type Position struct {
+	Offset  int          // Byte offset of the position in the mask string
+	Kind    PositionKind // What the position is made of
+	Symbol  byte         // Charset symbol (l, u, d, 1, ...) or the literal byte
+	Charset Charset      // The bytes the position can take
+}
+
+// String returns the position as it would be written in a mask
+func (p Position) String() string {
+	switch {
+	case p.Kind != PositionLiteral:
+		return "?" + string(p.Symbol)
+	case p.Symbol == '?':
+		return "??"
+	default:
+		return string(p.Symbol)
+	}
+}
+
+// Mask is a parsed hashcat mask
+type Mask struct {
+	Raw       string     // The mask as it was given
+	Positions []Position // The candidate positions, in order
+}
+
+// Parse parses a mask that only uses built-in charsets
+func Parse(mask string) (*Mask, error) {
+	return ParseWith(mask, Config{})
+}
+
+// ParseWith parses a mask using the custom charsets and settings in cfg
+func ParseWith(mask string, cfg Config) (*Mask, error) {
+	if mask == "" {
+		return nil, &ParseError{Input: mask, Pos: 0, Err: ErrEmptyMask}
+	}
+
+	custom, err := cfg.charsets()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Mask{Raw: mask}
+
+	for i := 0; i < len(mask); i++ {
+		c := mask[i]
+
+		if c == '?' {
+			if i+1 >= len(mask) {
+				return nil, &ParseError{Input: mask, Pos: i, Err: ErrIncompletePlaceholder}
+			}
+
+			symbol := mask[i+1]
+			charset, err := resolveSymbol(symbol, custom)
+			if err != nil {
+				return nil, &ParseError{Input: mask, Pos: i, Err: err}
+			}
+
+			kind := PositionBuiltin
+			switch {
+			case symbol == '?':
+				kind = PositionLiteral
+			case symbol >= '1' && symbol <= '4':
+				kind = PositionCustom
+			}
+
+			m.Positions = append(m.Positions, Position{Offset: i, Kind: kind, Symbol: symbol, Charset: charset})
+			i++
+			continue
+		}
+
+		if cfg.HexCharset {
+			value, err := parseHexByte(mask, i)
+			if err != nil {
+				return nil, err
+			}
+
+			m.Positions = append(m.Positions, Position{Offset: i, Kind: PositionLiteral, Symbol: value, Charset: Charset{value}})
+			i++
+			continue
+		}
+
+		m.Positions = append(m.Positions, Position{Offset: i, Kind: PositionLiteral, Symbol: c, Charset: Charset{c}})
+	}
+
+	return m, nil
+}
+
+// Validate checks that a mask is syntactically valid for the given configuration
+func Validate(mask string, cfg Config) error {
+	_, err := ParseWith(mask, cfg)
+	return err
+}
+
+// Len returns the length of the candidates generated by the mask
+func (m *Mask) Len() int {
+	return len(m.Positions)
+}
+
+// CustomCharsetRefs returns the custom charset numbers (1 to 4) referenced by the mask
+func (m *Mask) CustomCharsetRefs() []int {
+	var seen [4]bool
+	var refs []int
+
+	for _, position := range m.Positions {
+		if position.Kind != PositionCustom {
+			continue
+		}
+
+		n := int(position.Symbol - '0')
+		if !seen[n-1] {
+			seen[n-1] = true
+			refs = append(refs, n)
+		}
+	}
+
+	return refs
+}
+
+// String returns the mask in hashcat syntax
+func (m *Mask) String() string {
+	if m.Raw != "" {
+		return m.Raw
+	}
+
+	var sb strings.Builder
+	for _, position := range m.Positions {
+		sb.WriteString(position.String())
+	}
+	return sb.String()
+}
+
+// charsets parses the custom charset definitions of the configuration in order,
+// so that later definitions may reference earlier ones
+func (cfg Config) charsets() ([4]Charset, error) {
+	var custom [4]Charset
+
+	for i, definition := range cfg.CustomCharsets {
+		if definition == "" {
+			continue
+		}
+
+		charset, err := ParseCharset(definition, cfg.HexCharset, custom)
+		if err != nil {
+			return custom, fmt.Errorf("custom charset %d: %w", i+1, err)
+		}
+		custom[i] = charset
+	}
+
+	return custom, nil
+}
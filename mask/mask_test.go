@@ -0,0 +1,100 @@
+package mask
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseWith(t *testing.T) {
+	tests := []struct {
+		name     string
+		mask     string
+		cfg      Config
+		length   int
+		keyspace string
+		err      error
+	}{
+		{name: "escaped question mark", mask: "??", length: 1, keyspace: "1"},
+		{name: "escaped question mark after charset", mask: "?l??", length: 2, keyspace: "26"},
+		{name: "literals", mask: "pass?d", length: 5, keyspace: "10"},
+		{name: "all printable", mask: "?a", length: 1, keyspace: "95"},
+		{name: "binary", mask: "?b?b", length: 2, keyspace: "65536"},
+		{name: "custom charset", mask: "?1?d", cfg: Config{CustomCharsets: [4]string{"?l?u"}}, length: 2, keyspace: "520"},
+		{name: "custom charset duplicates", mask: "?1", cfg: Config{CustomCharsets: [4]string{"aab?d0"}}, length: 1, keyspace: "12"},
+		{name: "custom charset referencing earlier one", mask: "?2", cfg: Config{CustomCharsets: [4]string{"ab", "?1c"}}, length: 1, keyspace: "3"},
+		{name: "custom charset referencing later one", mask: "?1", cfg: Config{CustomCharsets: [4]string{"?2", "ab"}}, err: ErrUndefinedCharset},
+		{name: "undefined custom charset", mask: "?1", err: ErrUndefinedCharset},
+		{name: "undefined fourth charset", mask: "?1?4", cfg: Config{CustomCharsets: [4]string{"ab"}}, err: ErrUndefinedCharset},
+		{name: "hex literals", mask: "6162?d", cfg: Config{HexCharset: true}, length: 3, keyspace: "10"},
+		{name: "hex custom charset", mask: "?1?1", cfg: Config{CustomCharsets: [4]string{"616263"}, HexCharset: true}, length: 2, keyspace: "9"},
+		{name: "hex custom charset with placeholder", mask: "?1", cfg: Config{CustomCharsets: [4]string{"ff?d"}, HexCharset: true}, length: 1, keyspace: "11"},
+		{name: "hex odd length", mask: "616", cfg: Config{HexCharset: true}, err: ErrInvalidHex},
+		{name: "hex invalid digit", mask: "zz", cfg: Config{HexCharset: true}, err: ErrInvalidHex},
+		{name: "hex custom charset odd length", mask: "?1", cfg: Config{CustomCharsets: [4]string{"abc"}, HexCharset: true}, err: ErrInvalidHex},
+		{name: "empty", mask: "", err: ErrEmptyMask},
+		{name: "trailing question mark", mask: "?d?", err: ErrIncompletePlaceholder},
+		{name: "unknown charset", mask: "?x", err: ErrUnknownCharset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseWith(tt.mask, tt.cfg)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("ParseWith(%q) error = %v, want %v", tt.mask, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWith(%q) error = %v", tt.mask, err)
+			}
+
+			if m.Len() != tt.length || m.Keyspace().String() != tt.keyspace {
+				t.Errorf("ParseWith(%q) = length %d, keyspace %s, want length %d, keyspace %s",
+					tt.mask, m.Len(), m.Keyspace(), tt.length, tt.keyspace)
+			}
+		})
+	}
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	_, err := ParseWith("?d?d?5", Config{})
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Pos != 4 {
+		t.Errorf("ParseWith() error = %v, want a ParseError at position 4", err)
+	}
+}
+
+func TestIncrementKeyspace(t *testing.T) {
+	m, err := Parse("?d?d?d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		min, max int
+		total    string
+		err      error
+	}{
+		{min: 1, max: 3, total: "1110"},
+		{min: 2, max: 2, total: "100"},
+		{min: 0, max: 2, err: ErrInvalidIncrement},
+		{min: 3, max: 2, err: ErrInvalidIncrement},
+		{min: 1, max: 4, err: ErrInvalidIncrement},
+	}
+
+	for _, tt := range tests {
+		total, err := m.TotalKeyspace(tt.min, tt.max)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("TotalKeyspace(%d, %d) error = %v, want %v", tt.min, tt.max, err, tt.err)
+			}
+			continue
+		}
+
+		if err != nil || total.String() != tt.total {
+			t.Errorf("TotalKeyspace(%d, %d) = %v, %v, want %s", tt.min, tt.max, total, err, tt.total)
+		}
+	}
+}
@@ -17,6 +17,10 @@ type BenchmarkResult struct {
 	Loops        int     `json:"loops"`
 	Threads      int     `json:"threads"`
 	VectorSize   int     `json:"vector_size"`
+
+	// Clocks of the device during the benchmark, only reported with machine readable output
+	CoreClockMHz   int `json:"core_clock_mhz,omitempty"`
+	MemoryClockMHz int `json:"memory_clock_mhz,omitempty"`
 }
 
 // BenchmarkSummary represents the summarized benchmark results across all devices
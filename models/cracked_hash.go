@@ -4,5 +4,26 @@ package models
 type CrackedHash struct {
 	Hash     string `json:"hash"`
 	Password string `json:"password"`
+	Username string `json:"username,omitempty"`
 	Time     int64  `json:"time"`
+
+	// The exact bytes of the plain, which Password holds as a string
+	PlainBytes []byte `json:"plain_bytes,omitempty"`
+
+	// Fields written by hashcat depending on the outfile format
+	HexPlain     string `json:"hex_plain,omitempty"`     // The plain in hex
+	CrackPos     int64  `json:"crack_pos,omitempty"`     // Position of the plain in the keyspace
+	CrackedAt    int64  `json:"cracked_at,omitempty"`    // Unix time hashcat cracked the hash at
+	CrackedAfter int64  `json:"cracked_after,omitempty"` // Seconds between the session start and the crack
+
+	// Records of the hash file the hash was read from; several accounts may share a hash
+	Usernames []string     `json:"usernames,omitempty"`
+	Sources   []HashSource `json:"sources,omitempty"`
+}
+
+// HashSource is a line of a hash file
+type HashSource struct {
+	Line     int    `json:"line"`               // 1-based line number
+	Username string `json:"username,omitempty"` // Username of the line, with --username
+	Record   string `json:"record"`             // The line as it appears in the hash file
 }
@@ -1,12 +1,39 @@
 package models
 
-// Platform represents an OpenCL platform in hashcat
+// Backend is a compute API hashcat can run devices through
+type Backend string
+
+const (
+	BackendCUDA   Backend = "CUDA"
+	BackendHIP    Backend = "HIP"
+	BackendMetal  Backend = "Metal"
+	BackendOpenCL Backend = "OpenCL"
+)
+
+// Backends lists the backends hashcat supports
+var Backends = []Backend{BackendCUDA, BackendHIP, BackendMetal, BackendOpenCL}
+
+// Valid reports whether the backend is one hashcat supports
+func (b Backend) Valid() bool {
+	for _, backend := range Backends {
+		if b == backend {
+			return true
+		}
+	}
+	return false
+}
+
+// Platform represents an OpenCL platform in hashcat. The devices hashcat lists under
+// the CUDA, HIP and Metal backends are grouped into a platform for each backend, with
+// an ID of 0 and the backend's name.
 type Platform struct {
-	ID      int      `json:"id"`
-	Vendor  string   `json:"vendor"`
-	Name    string   `json:"name"`
-	Version string   `json:"version"`
-	Devices []Device `json:"devices,omitempty"`
+	ID         int               `json:"id"`
+	Backend    Backend           `json:"backend"`
+	Vendor     string            `json:"vendor"`
+	Name       string            `json:"name"`
+	Version    string            `json:"version"`
+	Properties map[string]string `json:"properties,omitempty"` // Every property as printed, keyed by name
+	Devices    []Device          `json:"devices,omitempty"`
 }
 
 // Device represents a backend device in hashcat
@@ -24,9 +51,47 @@ type Device struct {
 	LocalMemory   int    `json:"local_memory_kb"`
 	OpenCLVersion string `json:"opencl_version"`
 	DriverVersion string `json:"driver_version"`
+
+	Backend           Backend           `json:"backend"`
+	Aliases           []int             `json:"aliases,omitempty"`            // IDs of the same device under other backends
+	PCIAddress        string            `json:"pci_address,omitempty"`        // PCI bus address, as printed by hashcat
+	ComputeCapability string            `json:"compute_capability,omitempty"` // CUDA compute capability or equivalent
+	Skipped           bool              `json:"skipped,omitempty"`            // hashcat skips or ignores the device
+	Properties        map[string]string `json:"properties,omitempty"`         // Every property as printed, keyed by name
 }
 
 // DeviceList represents the response from hashcat when listing backend devices
 type DeviceList struct {
 	Platforms []Platform `json:"platforms"`
 }
+
+// Devices returns the devices of every platform, in the order hashcat lists them
+func (l *DeviceList) Devices() []Device {
+	var devices []Device
+	for _, platform := range l.Platforms {
+		devices = append(devices, platform.Devices...)
+	}
+	return devices
+}
+
+// Backends returns the backends that have at least one device
+func (l *DeviceList) Backends() []Backend {
+	var backends []Backend
+	for _, platform := range l.Platforms {
+		if len(platform.Devices) == 0 {
+			continue
+		}
+
+		found := false
+		for _, backend := range backends {
+			if backend == platform.Backend {
+				found = true
+				break
+			}
+		}
+		if !found {
+			backends = append(backends, platform.Backend)
+		}
+	}
+	return backends
+}
@@ -1,18 +1,83 @@
 package models
 
+// Kernel types a hash type can be run with
+const (
+	KernelPure      = "pure"
+	KernelOptimized = "optimized"
+)
+
 // HashType represents a hashcat hash type
 type HashType struct {
 	ID          int    `json:"id"`
 	Name        string `json:"name"`
 	Category    string `json:"category"`
 	Description string `json:"description"`
+
+	// Metadata reported by hashcat --hash-info
+	SlowHash          bool     `json:"slow_hash,omitempty"`
+	IsSalted          bool     `json:"is_salted,omitempty"`
+	SaltType          string   `json:"salt_type,omitempty"`
+	SaltLenMin        int      `json:"salt_len_min,omitempty"`
+	SaltLenMax        int      `json:"salt_len_max,omitempty"`
+	PasswordLenMin    int      `json:"password_len_min,omitempty"`
+	PasswordLenMax    int      `json:"password_len_max,omitempty"`
+	KernelTypes       []string `json:"kernel_types,omitempty"`
+	ExampleHashFormat string   `json:"example_hash_format,omitempty"`
+	ExampleHash       string   `json:"example_hash,omitempty"`
+	ExamplePass       string   `json:"example_pass,omitempty"`
+	BenchmarkMask     string   `json:"benchmark_mask,omitempty"`
+	BenchmarkCharset1 string   `json:"benchmark_charset1,omitempty"`
+	AutodetectEnabled bool     `json:"autodetect_enabled,omitempty"`
+	SelfTestEnabled   bool     `json:"self_test_enabled,omitempty"`
+	PotfileEnabled    bool     `json:"potfile_enabled,omitempty"`
+	CustomPlugin      bool     `json:"custom_plugin,omitempty"`
+	PlaintextEncoding []string `json:"plaintext_encoding,omitempty"`
+	DeprecatedNotice  string   `json:"deprecated_notice,omitempty"`
+}
+
+// SupportsKernel reports whether the hash type can be run with the given kernel type
+func (h *HashType) SupportsKernel(kernelType string) bool {
+	for _, supported := range h.KernelTypes {
+		if supported == kernelType {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsOptimizedKernel reports whether the hash type has an optimized kernel (-O)
+func (h *HashType) SupportsOptimizedKernel() bool {
+	return h.SupportsKernel(KernelOptimized)
+}
+
+// ExceedsPasswordLength reports whether passwords of the given length are longer
+// than the hash type supports. A zero maximum means the limit is unknown.
+func (h *HashType) ExceedsPasswordLength(length int) bool {
+	return h.PasswordLenMax > 0 && length > h.PasswordLenMax
+}
+
+// BelowPasswordLength reports whether passwords of the given length are shorter
+// than the hash type requires
+func (h *HashType) BelowPasswordLength(length int) bool {
+	return length < h.PasswordLenMin
+}
+
+// HasExample reports whether hashcat provides an example hash and password that can be
+// used to self-test the hash format
+func (h *HashType) HasExample() bool {
+	return h.ExampleHash != "" && h.ExamplePass != "" && (h.ExampleHashFormat == "" || h.ExampleHashFormat == "plain")
+}
+
+// IsDeprecated reports whether hashcat has marked the hash type as deprecated
+func (h *HashType) IsDeprecated() bool {
+	return h.DeprecatedNotice != ""
 }
 
-// HashMode represents a hashcat hash mode with its settings
+// HashMode represents a hashcat hash mode with its settings.
+// Whether the mode is salted comes from the embedded HashType.
 type HashMode struct {
 	HashType
 	IsOptimized bool `json:"is_optimized"`
-	IsSalted    bool `json:"is_salted"`
 }
 
 // HashFile represents a file containing hashes to be cracked
@@ -20,6 +85,7 @@ type HashFile struct {
 	Path     string `json:"path"`
 	HashType int    `json:"hash_type"`
 	Count    int    `json:"count,omitempty"`
+	Username bool   `json:"username,omitempty"` // Each line starts with a username (--username)
 }
 
 // HashcatSupportedHashes represents the response from hashcat when listing supported hash types
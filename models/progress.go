@@ -6,16 +6,33 @@ import "time"
 type CrackingStatus int
 
 const (
-	StatusUnknown   CrackingStatus = 0
-	StatusInit      CrackingStatus = 1
-	StatusRunning   CrackingStatus = 3
-	StatusExhausted CrackingStatus = 5
-	StatusCracked   CrackingStatus = 6
-	StatusAborted   CrackingStatus = 7
-	StatusQuit      CrackingStatus = 8
-	StatusPaused    CrackingStatus = 9
+	StatusUnknown               CrackingStatus = 0
+	StatusInit                  CrackingStatus = 1
+	StatusSelftest              CrackingStatus = 2
+	StatusRunning               CrackingStatus = 3
+	StatusPaused                CrackingStatus = 4
+	StatusExhausted             CrackingStatus = 5
+	StatusCracked               CrackingStatus = 6
+	StatusAborted               CrackingStatus = 7
+	StatusQuit                  CrackingStatus = 8
+	StatusBypass                CrackingStatus = 9
+	StatusAbortedCheckpoint     CrackingStatus = 10
+	StatusAbortedRuntime        CrackingStatus = 11
+	StatusRunningCheckpointQuit CrackingStatus = 12
+	StatusError                 CrackingStatus = 13
+	StatusAbortedFinish         CrackingStatus = 14
 )
 
+// IsFinal reports whether hashcat has stopped working on the attack in this status
+func (s CrackingStatus) IsFinal() bool {
+	switch s {
+	case StatusExhausted, StatusCracked, StatusAborted, StatusQuit,
+		StatusAbortedCheckpoint, StatusAbortedRuntime, StatusError, StatusAbortedFinish:
+		return true
+	}
+	return false
+}
+
 // HashcatGuess represents the current guess information during cracking
 type HashcatGuess struct {
 	GuessBase        string  `json:"guess_base"`
@@ -66,6 +83,9 @@ type ProgressStats struct {
 	TotalHashes        int           `json:"total_hashes"`
 	SaltsRecovered     int           `json:"salts_recovered"`
 	TotalSalts         int           `json:"total_salts"`
+	BaseOffset         int           `json:"base_offset"`
+	BaseCount          int           `json:"base_count"`
+	RestorePoint       int64         `json:"restore_point"`
 }
 
 // CalculateStats returns statistics based on the current progress
@@ -95,5 +115,8 @@ func (p *Progress) CalculateStats() ProgressStats {
 		TotalHashes:        p.RecoveredHashes[1],
 		SaltsRecovered:     p.RecoveredSalts[0],
 		TotalSalts:         p.RecoveredSalts[1],
+		BaseOffset:         p.Guess.GuessBaseOffset,
+		BaseCount:          p.Guess.GuessBaseCount,
+		RestorePoint:       p.RestorePoint,
 	}
 }
@@ -0,0 +1,106 @@
+package models
+
+import "time"
+
+// Unavailable marks a telemetry reading the device or driver does not report
+const Unavailable = -1
+
+// DeviceTelemetry is a snapshot of the hardware state of a device. Readings the
+// source does not provide are set to Unavailable.
+type DeviceTelemetry struct {
+	DeviceID       int       `json:"device_id"`
+	DeviceName     string    `json:"device_name,omitempty"`
+	Time           time.Time `json:"time"`
+	Temperature    int       `json:"temperature"`      // Degrees Celsius
+	FanSpeed       int       `json:"fan_speed"`        // Percent
+	Utilization    int       `json:"utilization"`      // Percent
+	PowerWatts     int       `json:"power_watts"`      // Watts
+	CoreClockMHz   int       `json:"core_clock_mhz"`   // Core clock
+	MemoryClockMHz int       `json:"memory_clock_mhz"` // Memory clock
+	BusLanes       int       `json:"bus_lanes"`        // PCIe lanes in use
+	Speed          int64     `json:"speed,omitempty"`  // Hashes per second, when sampled from a session
+}
+
+// NewDeviceTelemetry returns telemetry for a device with every reading unavailable
+func NewDeviceTelemetry(deviceID int, at time.Time) DeviceTelemetry {
+	return DeviceTelemetry{
+		DeviceID:       deviceID,
+		Time:           at,
+		Temperature:    Unavailable,
+		FanSpeed:       Unavailable,
+		Utilization:    Unavailable,
+		PowerWatts:     Unavailable,
+		CoreClockMHz:   Unavailable,
+		MemoryClockMHz: Unavailable,
+		BusLanes:       Unavailable,
+	}
+}
+
+// TelemetryFromStatus converts the device status of a progress update. The status only
+// carries the temperature, which hashcat omits when hardware monitoring is disabled,
+// and the utilization.
+func TelemetryFromStatus(status DeviceStatus, at time.Time) DeviceTelemetry {
+	telemetry := NewDeviceTelemetry(status.DeviceID, at)
+	telemetry.DeviceName = status.DeviceName
+	telemetry.Utilization = status.Utilization
+	telemetry.Speed = status.Speed
+
+	// A running device is never at 0°C; hashcat reports -1 when it cannot read it
+	if status.Temperature > 0 {
+		telemetry.Temperature = status.Temperature
+	}
+
+	return telemetry
+}
+
+// TelemetrySeries is the telemetry sampled for a device, oldest first
+type TelemetrySeries struct {
+	DeviceID int               `json:"device_id"`
+	Samples  []DeviceTelemetry `json:"samples"`
+}
+
+// Latest returns the most recent sample
+func (s *TelemetrySeries) Latest() (DeviceTelemetry, bool) {
+	if len(s.Samples) == 0 {
+		return DeviceTelemetry{}, false
+	}
+	return s.Samples[len(s.Samples)-1], true
+}
+
+// Since returns the samples taken at or after t
+func (s *TelemetrySeries) Since(t time.Time) []DeviceTelemetry {
+	for i, sample := range s.Samples {
+		if !sample.Time.Before(t) {
+			return s.Samples[i:]
+		}
+	}
+	return nil
+}
+
+// MaxTemperature returns the highest temperature sampled, or Unavailable
+func (s *TelemetrySeries) MaxTemperature() int {
+	highest := Unavailable
+	for _, sample := range s.Samples {
+		if sample.Temperature > highest {
+			highest = sample.Temperature
+		}
+	}
+	return highest
+}
+
+// AverageUtilization returns the mean utilization of the samples that report one,
+// or Unavailable
+func (s *TelemetrySeries) AverageUtilization() float64 {
+	var total, count int
+	for _, sample := range s.Samples {
+		if sample.Utilization != Unavailable {
+			total += sample.Utilization
+			count++
+		}
+	}
+
+	if count == 0 {
+		return Unavailable
+	}
+	return float64(total) / float64(count)
+}
@@ -0,0 +1,197 @@
+package hashcat
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pixelsquared/go-hashcat/models"
+	"github.com/pixelsquared/go-hashcat/potfile"
+)
+
+// OutfileField is a field hashcat can write for each cracked hash (--outfile-format)
+type OutfileField int
+
+// Outfile fields, numbered as hashcat numbers them
+const (
+	OutfileHash              OutfileField = 1 // The hash, including any salt
+	OutfilePlain             OutfileField = 2 // The plain
+	OutfileHexPlain          OutfileField = 3 // The plain in hex
+	OutfileCrackPos          OutfileField = 4 // Position of the plain in the keyspace
+	OutfileTimestampAbsolute OutfileField = 5 // Unix time the hash was cracked at
+	OutfileTimestampRelative OutfileField = 6 // Seconds since the session started
+)
+
+// OutfileFormat is the ordered list of fields written for each cracked hash
+type OutfileFormat []OutfileField
+
+// String returns the format as the value of --outfile-format
+func (f OutfileFormat) String() string {
+	fields := make([]string, len(f))
+	for i, field := range f {
+		fields[i] = strconv.Itoa(int(field))
+	}
+	return strings.Join(fields, ",")
+}
+
+// Validate checks that every field is known and used only once
+func (f OutfileFormat) Validate() error {
+	var seen [OutfileTimestampRelative + 1]bool
+
+	for _, field := range f {
+		if field < OutfileHash || field > OutfileTimestampRelative {
+			return fmt.Errorf("%w: unknown outfile field %d", ErrInvalidCrackOptions, field)
+		}
+		if seen[field] {
+			return fmt.Errorf("%w: outfile field %d is used more than once", ErrInvalidCrackOptions, field)
+		}
+		seen[field] = true
+	}
+
+	return nil
+}
+
+// DefaultOutfileFormat is the format sessions write their outfile in unless configured
+// otherwise: the hash, the plain in hex, the crack position and the time of the crack.
+// Only the hash can contain the separator, so every line can be split unambiguously.
+var DefaultOutfileFormat = OutfileFormat{OutfileHash, OutfileHexPlain, OutfileCrackPos, OutfileTimestampAbsolute}
+
+// hashcatOutfileFormat is the format hashcat uses when --outfile-format is not given
+var hashcatOutfileFormat = OutfileFormat{OutfileHash, OutfilePlain}
+
+// ParseOutfileLine parses a line of an outfile written with the given format and separator.
+// Only the hash may contain the separator; hashcat writes plains that contain it as
+// $HEX[...]. The fields after the hash are therefore taken from the end of the line and
+// those before it from the start. Plains are decoded into PlainBytes, from the hex plain
+// when the format has one.
+func ParseOutfileLine(line string, format OutfileFormat, separator byte) (*models.CrackedHash, error) {
+	if len(format) == 0 {
+		format = hashcatOutfileFormat
+	}
+
+	fields := make([]string, len(format))
+	rest := line
+
+	// Fields after the hash, from the last one backwards
+	hashIndex := -1
+	for i := len(format) - 1; i >= 0; i-- {
+		if format[i] == OutfileHash {
+			hashIndex = i
+			break
+		}
+
+		// Without a hash, the first field is what remains of the line
+		if i == 0 {
+			fields[i], rest = rest, ""
+			break
+		}
+
+		split := strings.LastIndexByte(rest, separator)
+		if split < 0 {
+			return nil, fmt.Errorf("%w: expected %d fields in %q", ErrInvalidOutfileLine, len(format), line)
+		}
+		fields[i], rest = rest[split+1:], rest[:split]
+	}
+
+	// Fields before the hash, from the first one forwards
+	for i := 0; i < hashIndex; i++ {
+		split := strings.IndexByte(rest, separator)
+		if split < 0 {
+			return nil, fmt.Errorf("%w: expected %d fields in %q", ErrInvalidOutfileLine, len(format), line)
+		}
+		fields[i], rest = rest[:split], rest[split+1:]
+	}
+
+	if hashIndex >= 0 {
+		fields[hashIndex] = rest
+	}
+
+	result := &models.CrackedHash{}
+	hasPlain := false
+
+	for i, field := range format {
+		value := fields[i]
+
+		switch field {
+		case OutfileHash:
+			result.Hash = value
+		case OutfilePlain:
+			plain, err := potfile.DecodePlain(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidOutfileLine, err)
+			}
+			result.Password = plain
+			hasPlain = true
+		case OutfileHexPlain:
+			result.HexPlain = value
+		case OutfileCrackPos:
+			position, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid crack position %q", ErrInvalidOutfileLine, value)
+			}
+			result.CrackPos = position
+		case OutfileTimestampAbsolute:
+			timestamp, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid timestamp %q", ErrInvalidOutfileLine, value)
+			}
+			result.CrackedAt = timestamp
+		case OutfileTimestampRelative:
+			elapsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid relative timestamp %q", ErrInvalidOutfileLine, value)
+			}
+			result.CrackedAfter = elapsed
+		}
+	}
+
+	// The hex plain holds the exact bytes, whatever the plain field contains
+	if slices.Contains(format, OutfileHexPlain) {
+		plain, err := hex.DecodeString(result.HexPlain)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid hex plain %q", ErrInvalidOutfileLine, result.HexPlain)
+		}
+		result.PlainBytes = plain
+		if !hasPlain {
+			result.Password = string(plain)
+		}
+	} else {
+		result.PlainBytes = []byte(result.Password)
+	}
+
+	return result, nil
+}
+
+// ReadOutfile reads every cracked hash of an outfile written with the given format
+func ReadOutfile(r io.Reader, format OutfileFormat, separator byte) ([]*models.CrackedHash, error) {
+	var results []*models.CrackedHash
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if line == "" {
+			continue
+		}
+
+		result, err := ParseOutfileLine(line, format, separator)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		results = append(results, result)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read outfile: %w", err)
+	}
+
+	return results, nil
+}
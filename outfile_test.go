@@ -0,0 +1,173 @@
+package hashcat
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pixelsquared/go-hashcat/models"
+)
+
+func TestParseOutfileLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		format    OutfileFormat
+		separator byte
+		want      models.CrackedHash
+		err       error
+	}{
+		{
+			name:   "default format",
+			line:   "5f4dcc3b5aa765d61d8327deb882cf99:70617373776f7264:1234:1700000000",
+			format: DefaultOutfileFormat,
+			want: models.CrackedHash{Hash: "5f4dcc3b5aa765d61d8327deb882cf99", Password: "password", PlainBytes: []byte("password"),
+				HexPlain: "70617373776f7264", CrackPos: 1234, CrackedAt: 1700000000},
+		},
+		{
+			name:   "default format with salted hash",
+			line:   "hash:salt:more:70613a7373:5:1700000000",
+			format: DefaultOutfileFormat,
+			want: models.CrackedHash{Hash: "hash:salt:more", Password: "pa:ss", PlainBytes: []byte("pa:ss"),
+				HexPlain: "70613a7373", CrackPos: 5, CrackedAt: 1700000000},
+		},
+		{
+			name: "hashcat format",
+			line: "hash:password",
+			want: models.CrackedHash{Hash: "hash", Password: "password", PlainBytes: []byte("password")},
+		},
+		{
+			name: "hashcat format with salted hash",
+			line: "hash:salt:password",
+			want: models.CrackedHash{Hash: "hash:salt", Password: "password", PlainBytes: []byte("password")},
+		},
+		{
+			name: "hashcat format with separator in plain",
+			line: "hash:salt:$HEX[70613a7373]",
+			want: models.CrackedHash{Hash: "hash:salt", Password: "pa:ss", PlainBytes: []byte("pa:ss")},
+		},
+		{
+			name:   "plain before hash",
+			line:   "$HEX[70613a7373]:hash:salt",
+			format: OutfileFormat{OutfilePlain, OutfileHash},
+			want:   models.CrackedHash{Hash: "hash:salt", Password: "pa:ss", PlainBytes: []byte("pa:ss")},
+		},
+		{
+			name:   "fields on both sides of hash",
+			line:   "42:hash:salt:70617373:1700000000",
+			format: OutfileFormat{OutfileCrackPos, OutfileHash, OutfileHexPlain, OutfileTimestampAbsolute},
+			want: models.CrackedHash{Hash: "hash:salt", Password: "pass", PlainBytes: []byte("pass"),
+				HexPlain: "70617373", CrackPos: 42, CrackedAt: 1700000000},
+		},
+		{
+			name:   "plain and hex plain",
+			line:   "hash:salt:pass:70617373:12",
+			format: OutfileFormat{OutfileHash, OutfilePlain, OutfileHexPlain, OutfileTimestampRelative},
+			want: models.CrackedHash{Hash: "hash:salt", Password: "pass", PlainBytes: []byte("pass"),
+				HexPlain: "70617373", CrackedAfter: 12},
+		},
+		{
+			name:   "without hash",
+			line:   "pass:70617373",
+			format: OutfileFormat{OutfilePlain, OutfileHexPlain},
+			want:   models.CrackedHash{Password: "pass", PlainBytes: []byte("pass"), HexPlain: "70617373"},
+		},
+		{
+			name:   "hex plain only",
+			line:   "70613a7373",
+			format: OutfileFormat{OutfileHexPlain},
+			want:   models.CrackedHash{Password: "pa:ss", PlainBytes: []byte("pa:ss"), HexPlain: "70613a7373"},
+		},
+		{
+			name:   "plain only",
+			line:   "pa:ss",
+			format: OutfileFormat{OutfilePlain},
+			want:   models.CrackedHash{Password: "pa:ss", PlainBytes: []byte("pa:ss")},
+		},
+		{
+			name:   "binary hex plain",
+			line:   "hash:00ff",
+			format: OutfileFormat{OutfileHash, OutfileHexPlain},
+			want:   models.CrackedHash{Hash: "hash", Password: "\x00\xff", PlainBytes: []byte{0x00, 0xff}, HexPlain: "00ff"},
+		},
+		{
+			name:      "other separator",
+			line:      "hash;salt;$HEX[70613b7373];7",
+			format:    OutfileFormat{OutfileHash, OutfilePlain, OutfileCrackPos},
+			separator: ';',
+			want:      models.CrackedHash{Hash: "hash;salt", Password: "pa;ss", PlainBytes: []byte("pa;ss"), CrackPos: 7},
+		},
+		{
+			name:   "missing fields",
+			line:   "70617373:1700000000",
+			format: DefaultOutfileFormat,
+			err:    ErrInvalidOutfileLine,
+		},
+		{
+			name:   "missing field before hash",
+			line:   "hash",
+			format: OutfileFormat{OutfileCrackPos, OutfileHash},
+			err:    ErrInvalidOutfileLine,
+		},
+		{
+			name:   "invalid crack position",
+			line:   "hash:70617373:x:1700000000",
+			format: DefaultOutfileFormat,
+			err:    ErrInvalidOutfileLine,
+		},
+		{
+			name:   "invalid hex plain",
+			line:   "hash:7061737:1:1700000000",
+			format: DefaultOutfileFormat,
+			err:    ErrInvalidOutfileLine,
+		},
+		{
+			name: "invalid $HEX plain",
+			line: "hash:$HEX[zz]",
+			err:  ErrInvalidOutfileLine,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			separator := tt.separator
+			if separator == 0 {
+				separator = ':'
+			}
+
+			got, err := ParseOutfileLine(tt.line, tt.format, separator)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("ParseOutfileLine(%q) error = %v, want %v", tt.line, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseOutfileLine(%q) error = %v", tt.line, err)
+			}
+
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ParseOutfileLine(%q) = %+v, want %+v", tt.line, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadOutfile(t *testing.T) {
+	input := "hash1:70617373:1:1700000000\r\n\nhash2:salt:70613a7373:2:1700000001\n"
+
+	results, err := ReadOutfile(strings.NewReader(input), DefaultOutfileFormat, ':')
+	if err != nil {
+		t.Fatalf("ReadOutfile() error = %v", err)
+	}
+
+	if len(results) != 2 || results[0].Password != "pass" || results[1].Hash != "hash2:salt" || results[1].Password != "pa:ss" {
+		t.Errorf("ReadOutfile() = %+v", results)
+	}
+
+	_, err = ReadOutfile(strings.NewReader("hash1:70617373:1:1700000000\nhash2\n"), DefaultOutfileFormat, ':')
+	if !errors.Is(err, ErrInvalidOutfileLine) || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("ReadOutfile() error = %v, want an invalid line error on line 2", err)
+	}
+}
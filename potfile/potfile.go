@@ -0,0 +1,363 @@
+// Package potfile reads, writes and queries hashcat potfiles.
+//
+// A potfile holds one cracked hash per line, followed by the separator and the plain:
+//
+//	5f4dcc3b5aa765d61d8327deb882cf99:password
+//	b305cadbb3bce54f3aa59c64fec00dea:$HEX[70613a7373]
+//
+// The hash itself may contain the separator (salted hashes do), so each line is split on
+// its last unescaped separator. Plains that contain the separator or non-printable bytes
+// are written as $HEX[...], the same way hashcat writes them.
+//
+//	pot, err := potfile.ReadFile("hashcat.potfile")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if entry, ok := pot.Lookup("5f4dcc3b5aa765d61d8327deb882cf99"); ok {
+//	    fmt.Println(entry.Plain) // password
+//	}
+package potfile
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// DefaultSeparator is the separator hashcat uses between hash and plain (--separator)
+const DefaultSeparator = ':'
+
+// Errors reported while reading potfiles
+var (
+	ErrMissingSeparator = errors.New("missing separator between hash and plain")
+	ErrInvalidHexPlain  = errors.New("invalid $HEX[] plain")
+)
+
+// LineError reports an invalid line of a potfile
+type LineError struct {
+	Line int   // 1-based line number
+	Err  error // The underlying error
+}
+
+// Error implements the error interface
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap implements the error unwrapping interface
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Entry is a single cracked hash of a potfile
+type Entry struct {
+	Hash  string // The hash as hashcat wrote it, including any salt
+	Plain string // The decoded plain, which may contain arbitrary bytes
+}
+
+// Potfile is an ordered, deduplicated set of potfile entries
+type Potfile struct {
+	Separator byte // Separator between hash and plain
+
+	entries []Entry
+	seen    map[Entry]bool
+	hashes  map[string][]int // Indexes of the entries of each hash
+}
+
+// New returns an empty potfile using the default separator
+func New() *Potfile {
+	return NewWithSeparator(DefaultSeparator)
+}
+
+// NewWithSeparator returns an empty potfile using the given separator
+func NewWithSeparator(separator byte) *Potfile {
+	return &Potfile{
+		Separator: separator,
+		seen:      make(map[Entry]bool),
+		hashes:    make(map[string][]int),
+	}
+}
+
+// Read reads a potfile that uses the default separator.
+// Empty lines are skipped and duplicate entries are only kept once.
+func Read(r io.Reader) (*Potfile, error) {
+	return ReadWithSeparator(r, DefaultSeparator)
+}
+
+// ReadWithSeparator reads a potfile that uses the given separator
+func ReadWithSeparator(r io.Reader, separator byte) (*Potfile, error) {
+	pot := NewWithSeparator(separator)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if line == "" {
+			continue
+		}
+
+		entry, err := ParseLine(line, separator)
+		if err != nil {
+			return nil, &LineError{Line: lineNumber, Err: err}
+		}
+
+		pot.Add(entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read potfile: %w", err)
+	}
+
+	return pot, nil
+}
+
+// ReadFile reads a potfile that uses the default separator
+func ReadFile(path string) (*Potfile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open potfile: %w", err)
+	}
+	defer file.Close()
+
+	return Read(file)
+}
+
+// ParseLine parses a single potfile line, splitting it on the last unescaped separator
+func ParseLine(line string, separator byte) (Entry, error) {
+	split := lastUnescaped(line, separator)
+	if split < 0 {
+		return Entry{}, ErrMissingSeparator
+	}
+
+	plain, err := DecodePlain(unescape(line[split+1:], separator))
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return Entry{Hash: line[:split], Plain: plain}, nil
+}
+
+// DecodePlain decodes a plain written as $HEX[...], returning other plains unchanged
+func DecodePlain(plain string) (string, error) {
+	if !strings.HasPrefix(plain, "$HEX[") || !strings.HasSuffix(plain, "]") {
+		return plain, nil
+	}
+
+	decoded, err := hex.DecodeString(plain[len("$HEX[") : len(plain)-1])
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidHexPlain, err)
+	}
+
+	return string(decoded), nil
+}
+
+// EncodePlain returns the plain as hashcat writes it, using $HEX[...] when the plain
+// contains the separator, non-printable bytes or could be mistaken for a $HEX[] plain
+func EncodePlain(plain string, separator byte) string {
+	if !needsHex(plain, separator) {
+		return plain
+	}
+
+	return "$HEX[" + hex.EncodeToString([]byte(plain)) + "]"
+}
+
+// String returns the entry as a potfile line using the default separator
+func (e Entry) String() string {
+	return e.Format(DefaultSeparator)
+}
+
+// Format returns the entry as a potfile line using the given separator
+func (e Entry) Format(separator byte) string {
+	return e.Hash + string(separator) + EncodePlain(e.Plain, separator)
+}
+
+// Add adds an entry, reporting whether it was not already present
+func (p *Potfile) Add(entry Entry) bool {
+	p.init()
+
+	if p.seen[entry] {
+		return false
+	}
+
+	p.seen[entry] = true
+	p.hashes[entry.Hash] = append(p.hashes[entry.Hash], len(p.entries))
+	p.entries = append(p.entries, entry)
+
+	return true
+}
+
+// Merge adds the entries of other potfiles, returning the number of new entries
+func (p *Potfile) Merge(others ...*Potfile) int {
+	added := 0
+
+	for _, other := range others {
+		for _, entry := range other.entries {
+			if p.Add(entry) {
+				added++
+			}
+		}
+	}
+
+	return added
+}
+
+// Lookup returns the first entry for a hash
+func (p *Potfile) Lookup(hash string) (Entry, bool) {
+	indexes := p.hashes[hash]
+	if len(indexes) == 0 {
+		return Entry{}, false
+	}
+
+	return p.entries[indexes[0]], true
+}
+
+// LookupAll returns every entry for a hash, as hashes with collisions may have several plains
+func (p *Potfile) LookupAll(hash string) []Entry {
+	indexes := p.hashes[hash]
+	entries := make([]Entry, 0, len(indexes))
+
+	for _, index := range indexes {
+		entries = append(entries, p.entries[index])
+	}
+
+	return entries
+}
+
+// Contains reports whether the potfile has an entry for a hash
+func (p *Potfile) Contains(hash string) bool {
+	return len(p.hashes[hash]) > 0
+}
+
+// Entries returns the entries in the order they were added
+func (p *Potfile) Entries() []Entry {
+	return append([]Entry(nil), p.entries...)
+}
+
+// Len returns the number of entries
+func (p *Potfile) Len() int {
+	return len(p.entries)
+}
+
+// WriteTo writes the potfile in hashcat format
+func (p *Potfile) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var written int64
+
+	for _, entry := range p.entries {
+		n, err := bw.WriteString(entry.Format(p.separator()) + "\n")
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, bw.Flush()
+}
+
+// WriteFile writes the potfile to path, replacing any existing file
+func (p *Potfile) WriteFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create potfile: %w", err)
+	}
+
+	if _, err := p.WriteTo(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write potfile: %w", err)
+	}
+
+	return file.Close()
+}
+
+// MergeFiles merges the potfiles at the given paths into dst, creating it if needed,
+// and returns the number of entries that were not already in dst
+func MergeFiles(dst string, srcs ...string) (int, error) {
+	pot, err := ReadFile(dst)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return 0, err
+		}
+		pot = New()
+	}
+
+	added := 0
+	for _, src := range srcs {
+		other, err := ReadFile(src)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", src, err)
+		}
+		added += pot.Merge(other)
+	}
+
+	if err := pot.WriteFile(dst); err != nil {
+		return 0, err
+	}
+
+	return added, nil
+}
+
+// init prepares the indexes of a potfile that was not created with New
+func (p *Potfile) init() {
+	if p.seen == nil {
+		p.seen = make(map[Entry]bool)
+		p.hashes = make(map[string][]int)
+	}
+}
+
+// separator returns the separator of the potfile, falling back to the default one
+func (p *Potfile) separator() byte {
+	if p.Separator == 0 {
+		return DefaultSeparator
+	}
+	return p.Separator
+}
+
+// lastUnescaped returns the index of the last separator not preceded by a backslash
+func lastUnescaped(line string, separator byte) int {
+	for i := len(line) - 1; i >= 0; i-- {
+		if line[i] != separator {
+			continue
+		}
+
+		// Count the backslashes before the separator; an odd number escapes it
+		backslashes := 0
+		for j := i - 1; j >= 0 && line[j] == '\\'; j-- {
+			backslashes++
+		}
+
+		if backslashes%2 == 0 {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// unescape replaces escaped separators in a plain with the separator itself
+func unescape(plain string, separator byte) string {
+	return strings.ReplaceAll(plain, "\\"+string(separator), string(separator))
+}
+
+// needsHex reports whether a plain has to be written as $HEX[...]
+func needsHex(plain string, separator byte) bool {
+	if strings.HasPrefix(plain, "$HEX[") {
+		return true
+	}
+
+	for i := 0; i < len(plain); i++ {
+		c := plain[i]
+		if c < 0x20 || c > 0x7e || c == separator {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,161 @@
+package potfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		separator byte
+		want      Entry
+		err       error
+	}{
+		{name: "plain", line: "5f4dcc3b5aa765d61d8327deb882cf99:password", want: Entry{Hash: "5f4dcc3b5aa765d61d8327deb882cf99", Plain: "password"}},
+		{name: "salted hash", line: "hash:salt:password", want: Entry{Hash: "hash:salt", Plain: "password"}},
+		{name: "empty plain", line: "hash:", want: Entry{Hash: "hash", Plain: ""}},
+		{name: "escaped separator in plain", line: `hash:pa\:ss`, want: Entry{Hash: "hash", Plain: "pa:ss"}},
+		{name: "escaped separator at end", line: `hash:salt:pass\:`, want: Entry{Hash: "hash:salt", Plain: "pass:"}},
+		{name: "escaped backslash before separator", line: `hash:pa\\:ss`, want: Entry{Hash: `hash:pa\\`, Plain: "ss"}},
+		{name: "other separator", line: "hash;salt;password", separator: ';', want: Entry{Hash: "hash;salt", Plain: "password"}},
+		{name: "hex plain", line: "hash:$HEX[70613a7373]", want: Entry{Hash: "hash", Plain: "pa:ss"}},
+		{name: "empty hex plain", line: "hash:$HEX[]", want: Entry{Hash: "hash", Plain: ""}},
+		{name: "unterminated hex plain", line: "hash:$HEX[7061", want: Entry{Hash: "hash", Plain: "$HEX[7061"}},
+		{name: "odd length hex plain", line: "hash:$HEX[706]", err: ErrInvalidHexPlain},
+		{name: "invalid hex plain", line: "hash:$HEX[zz]", err: ErrInvalidHexPlain},
+		{name: "missing separator", line: "hash", err: ErrMissingSeparator},
+		{name: "only escaped separator", line: `hash\:plain`, err: ErrMissingSeparator},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			separator := tt.separator
+			if separator == 0 {
+				separator = DefaultSeparator
+			}
+
+			got, err := ParseLine(tt.line, separator)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("ParseLine(%q) error = %v, want %v", tt.line, err, tt.err)
+				}
+				return
+			}
+
+			if err != nil || got != tt.want {
+				t.Errorf("ParseLine(%q) = %+v, %v, want %+v", tt.line, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodePlain(t *testing.T) {
+	tests := []struct {
+		plain string
+		want  string
+	}{
+		{plain: "password", want: "password"},
+		{plain: "pa:ss", want: "$HEX[70613a7373]"},
+		{plain: "tab\there", want: "$HEX[7461620968657265]"},
+		{plain: "$HEX[41]", want: "$HEX[244845585b34315d]"},
+		{plain: "caf\xc3\xa9", want: "$HEX[636166c3a9]"},
+	}
+
+	for _, tt := range tests {
+		got := EncodePlain(tt.plain, DefaultSeparator)
+		if got != tt.want {
+			t.Errorf("EncodePlain(%q) = %q, want %q", tt.plain, got, tt.want)
+		}
+
+		decoded, err := DecodePlain(got)
+		if err != nil || decoded != tt.plain {
+			t.Errorf("DecodePlain(%q) = %q, %v, want %q", got, decoded, err, tt.plain)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := New()
+	a.Add(Entry{Hash: "h1", Plain: "p1"})
+	a.Add(Entry{Hash: "h2", Plain: "p2"})
+
+	b := New()
+	b.Add(Entry{Hash: "h1", Plain: "p1"})
+	b.Add(Entry{Hash: "h1", Plain: "p3"})
+	b.Add(Entry{Hash: "h3", Plain: "p4"})
+	if b.Add(Entry{Hash: "h3", Plain: "p4"}) {
+		t.Error("Add() of a duplicate entry reported it as new")
+	}
+
+	if added := a.Merge(b); added != 2 {
+		t.Errorf("Merge() = %d, want 2", added)
+	}
+	if added := a.Merge(b); added != 0 {
+		t.Errorf("second Merge() = %d, want 0", added)
+	}
+
+	want := []Entry{{"h1", "p1"}, {"h2", "p2"}, {"h1", "p3"}, {"h3", "p4"}}
+	if got := a.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries() = %v, want %v", got, want)
+	}
+
+	if got := a.LookupAll("h1"); !reflect.DeepEqual(got, []Entry{{"h1", "p1"}, {"h1", "p3"}}) {
+		t.Errorf("LookupAll(h1) = %v", got)
+	}
+}
+
+func TestMergeFiles(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "merged.potfile")
+	src1 := filepath.Join(dir, "a.potfile")
+	src2 := filepath.Join(dir, "b.potfile")
+
+	write := func(path string, lines ...string) {
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(src1, "h1:p1", "h2:$HEX[70613a7373]")
+	write(src2, "h2:$HEX[70613a7373]", "h3:salt:p3", "", "h1:p1")
+
+	added, err := MergeFiles(dst, src1, src2)
+	if err != nil || added != 3 {
+		t.Fatalf("MergeFiles() = %d, %v, want 3", added, err)
+	}
+
+	added, err = MergeFiles(dst, src1, src2)
+	if err != nil || added != 0 {
+		t.Fatalf("second MergeFiles() = %d, %v, want 0", added, err)
+	}
+
+	data, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "h1:p1\nh2:$HEX[70613a7373]\nh3:salt:p3\n"
+	if string(data) != want {
+		t.Errorf("merged potfile = %q, want %q", data, want)
+	}
+}
+
+func TestMergeFilesInvalidSource(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "bad.potfile")
+	if err := os.WriteFile(src, []byte("h1:p1\nh2:$HEX[7]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := MergeFiles(filepath.Join(dir, "merged.potfile"), src)
+
+	var lineErr *LineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 2 || !errors.Is(err, ErrInvalidHexPlain) {
+		t.Errorf("MergeFiles() error = %v, want an invalid hex error on line 2", err)
+	}
+}
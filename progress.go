@@ -0,0 +1,134 @@
+package hashcat
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pixelsquared/go-hashcat/models"
+)
+
+// defaultProgressBuffer is the number of progress updates the Progress channel holds
+// with the ring buffer policy when no size is given
+const defaultProgressBuffer = 10
+
+// ProgressDelivery selects how progress updates are delivered on the Progress channel
+type ProgressDelivery int
+
+const (
+	// ProgressRing buffers the latest updates, discarding the oldest one when the
+	// buffer is full. Discarded updates are counted by DroppedProgress.
+	ProgressRing ProgressDelivery = iota
+
+	// ProgressLossless delivers every update, blocking the reading of hashcat's output
+	// until the update has been received. The channel must be read until it is closed,
+	// or the session canceled or stopped. Once the session is being stopped, updates
+	// that are not received right away are dropped and counted by DroppedProgress.
+	ProgressLossless
+
+	// ProgressLatest only keeps the most recent update on the channel, replacing any
+	// update that has not been received yet. Replaced updates are counted by
+	// DroppedProgress.
+	ProgressLatest
+)
+
+// String returns the name of the delivery policy
+func (d ProgressDelivery) String() string {
+	switch d {
+	case ProgressRing:
+		return "ring"
+	case ProgressLossless:
+		return "lossless"
+	case ProgressLatest:
+		return "latest"
+	}
+	return fmt.Sprintf("ProgressDelivery(%d)", int(d))
+}
+
+// Validate checks that the delivery policy is known
+func (d ProgressDelivery) Validate() error {
+	switch d {
+	case ProgressRing, ProgressLossless, ProgressLatest:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown progress delivery %d", ErrInvalidCrackOptions, int(d))
+}
+
+// progressFeed delivers the progress updates of a session according to its policy and
+// keeps the latest one for LatestProgress
+type progressFeed struct {
+	policy   ProgressDelivery
+	ch       chan *models.Progress
+	latest   atomic.Pointer[models.Progress]
+	dropped  atomic.Uint64
+	released chan struct{}
+	once     sync.Once
+}
+
+// newProgressFeed returns a feed for the policy. size is the capacity of the channel
+// with the ring buffer policy, defaulting to defaultProgressBuffer when not positive.
+func newProgressFeed(policy ProgressDelivery, size int) *progressFeed {
+	switch policy {
+	case ProgressLossless:
+		size = 0
+	case ProgressLatest:
+		size = 1
+	default:
+		if size <= 0 {
+			size = defaultProgressBuffer
+		}
+	}
+
+	return &progressFeed{
+		policy:   policy,
+		ch:       make(chan *models.Progress, size),
+		released: make(chan struct{}),
+	}
+}
+
+// publish records an update as the latest one and delivers it. Only the lossless policy
+// blocks, until the update is received, done is closed or the feed is released.
+func (f *progressFeed) publish(progress *models.Progress, done <-chan struct{}) {
+	f.latest.Store(progress)
+
+	if f.policy == ProgressLossless {
+		select {
+		case f.ch <- progress:
+		case <-done:
+		case <-f.released:
+			// The reader may be the one stopping the session, so stop waiting for it
+			select {
+			case f.ch <- progress:
+			default:
+				f.dropped.Add(1)
+			}
+		}
+		return
+	}
+
+	// Make room by discarding the oldest update. Neither step blocks, so a reader
+	// receiving at the same time cannot stall the session.
+	for {
+		select {
+		case f.ch <- progress:
+			return
+		default:
+		}
+
+		select {
+		case <-f.ch:
+			f.dropped.Add(1)
+		default:
+		}
+	}
+}
+
+// release stops publish from blocking on the reader, as the session is being stopped
+func (f *progressFeed) release() {
+	f.once.Do(func() { close(f.released) })
+}
+
+// close closes the channel once no more updates will be published
+func (f *progressFeed) close() {
+	close(f.ch)
+}
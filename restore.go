@@ -0,0 +1,217 @@
+package hashcat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sessionState is the information persisted for a session so that it can be restored
+// after the process that started it has gone away
+type sessionState struct {
+	Name          string          `json:"name"`
+	RestoreFile   string          `json:"restore_file"`
+	HashFile      string          `json:"hash_file"`
+	OwnsHashFile  bool            `json:"owns_hash_file"`
+	OutputFile    string          `json:"output_file"`
+	PotFile       string          `json:"pot_file"`
+	SharedPotFile bool            `json:"shared_pot_file,omitempty"`
+	TempFiles     []string        `json:"temp_files,omitempty"`
+	Options       *CrackOptions   `json:"options"`
+	Attack        *attackEnvelope `json:"attack,omitempty"`
+}
+
+// attackEnvelope stores a typed attack together with the name of its type
+type attackEnvelope struct {
+	Type   string          `json:"type"`
+	Attack json.RawMessage `json:"attack"`
+}
+
+// attackTypes creates an empty attack for each persisted attack type name
+var attackTypes = map[string]func() Attack{
+	"straight":             func() Attack { return &StraightAttack{} },
+	"combinator":           func() Attack { return &CombinatorAttack{} },
+	"brute-force":          func() Attack { return &BruteForceAttack{} },
+	"mask-list":            func() Attack { return &MaskListAttack{} },
+	"hybrid-wordlist-mask": func() Attack { return &HybridWordlistMaskAttack{} },
+	"hybrid-mask-wordlist": func() Attack { return &HybridMaskWordlistAttack{} },
+	"association":          func() Attack { return &AssociationAttack{} },
+}
+
+// attackTypeName returns the persisted type name of an attack
+func attackTypeName(attack Attack) (string, error) {
+	switch attack.(type) {
+	case *StraightAttack:
+		return "straight", nil
+	case *CombinatorAttack:
+		return "combinator", nil
+	case *BruteForceAttack:
+		return "brute-force", nil
+	case *MaskListAttack:
+		return "mask-list", nil
+	case *HybridWordlistMaskAttack:
+		return "hybrid-wordlist-mask", nil
+	case *HybridMaskWordlistAttack:
+		return "hybrid-mask-wordlist", nil
+	case *AssociationAttack:
+		return "association", nil
+	default:
+		return "", fmt.Errorf("%w: unsupported attack type %T", ErrInvalidAttack, attack)
+	}
+}
+
+// RestoreSession resumes a session that was interrupted before it completed. The returned
+// session runs hashcat with --restore and streams progress and results like a new one.
+func (c *HashcatClient) RestoreSession(ctx context.Context, name string) (CrackSession, error) {
+	if err := validateSessionName(name); err != nil {
+		return nil, err
+	}
+
+	state, err := c.loadState(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(state.RestoreFile); err != nil {
+		return nil, fmt.Errorf("%w: restore file for %s: %v", ErrSessionNotFound, name, err)
+	}
+
+	// Only restorable sessions save their state
+	state.Options.Restorable = true
+
+	// Sessions saved without an outfile format let hashcat write its own default
+	if len(state.Options.OutfileFormat) == 0 {
+		state.Options.OutfileFormat = hashcatOutfileFormat
+	}
+
+	session := c.newSession(ctx, state.Name, state.HashFile, state.Options)
+	session.restoring = true
+	session.ownsHashFile = state.OwnsHashFile
+	session.outputFile = state.OutputFile
+	session.potFile = state.PotFile
+	session.sharedPotFile = state.SharedPotFile
+	session.restoreFile = state.RestoreFile
+	session.tempFiles = state.TempFiles
+
+	return session, nil
+}
+
+// saveState writes the session state next to the restore file
+func (s *HashcatCrackSession) saveState() error {
+	state := &sessionState{
+		Name:          s.sessionName,
+		RestoreFile:   s.restoreFile,
+		HashFile:      s.hashFile,
+		OwnsHashFile:  s.ownsHashFile,
+		OutputFile:    s.outputFile,
+		PotFile:       s.potFile,
+		SharedPotFile: s.sharedPotFile,
+		TempFiles:     s.tempFiles,
+		Options:       s.options,
+	}
+
+	if s.options.Attack != nil {
+		typeName, err := attackTypeName(s.options.Attack)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(s.options.Attack)
+		if err != nil {
+			return fmt.Errorf("failed to encode attack: %w", err)
+		}
+
+		state.Attack = &attackEnvelope{Type: typeName, Attack: data}
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session state: %w", err)
+	}
+
+	if err := os.WriteFile(s.client.sessionStatePath(s.sessionName), data, 0600); err != nil {
+		return fmt.Errorf("failed to save session state: %w", err)
+	}
+
+	return nil
+}
+
+// loadState reads the saved state of a session
+func (c *HashcatClient) loadState(name string) (*sessionState, error) {
+	data, err := os.ReadFile(c.sessionStatePath(name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, name)
+		}
+		return nil, fmt.Errorf("failed to read session state: %w", err)
+	}
+
+	var state sessionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse session state: %w", err)
+	}
+
+	if state.Options == nil {
+		state.Options = &CrackOptions{}
+	}
+
+	if state.Attack != nil {
+		newAttack, ok := attackTypes[state.Attack.Type]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown attack type %q", ErrInvalidAttack, state.Attack.Type)
+		}
+
+		attack := newAttack()
+		if err := json.Unmarshal(state.Attack.Attack, attack); err != nil {
+			return nil, fmt.Errorf("failed to parse attack: %w", err)
+		}
+		state.Options.Attack = attack
+	}
+
+	return &state, nil
+}
+
+// validateSessionName checks that a session name can only name files inside the output
+// directory, as the state and restore files are named after it
+func validateSessionName(name string) error {
+	if name == "" || name == "." || strings.Contains(name, "..") || strings.ContainsAny(name, `/\`) || filepath.Base(name) != name {
+		return fmt.Errorf("%w: %q", ErrInvalidSessionName, name)
+	}
+	return nil
+}
+
+// sessionStatePath returns the path of the saved state for a session
+func (c *HashcatClient) sessionStatePath(name string) string {
+	return absPath(filepath.Join(c.config.OutputDir, name+".session.json"))
+}
+
+// restoreFilePath returns the path of the hashcat restore file for a session, which is
+// kept in the output directory for restorable sessions and in the system temporary
+// directory otherwise
+func (c *HashcatClient) restoreFilePath(name string, restorable bool) string {
+	if !restorable {
+		return filepath.Join(os.TempDir(), name+".restore")
+	}
+	return absPath(filepath.Join(c.config.OutputDir, name+".restore"))
+}
+
+// sessionDir returns the directory the files of a new session are created in: the output
+// directory for restorable sessions, or the system temporary directory
+func (c *HashcatClient) sessionDir(options *CrackOptions) string {
+	if options != nil && options.Restorable {
+		return c.config.OutputDir
+	}
+	return ""
+}
+
+// absPath returns the absolute form of path, or path itself if it cannot be determined
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
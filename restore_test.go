@@ -0,0 +1,32 @@
+package hashcat
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateSessionName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{name: "my-session", valid: true},
+		{name: "hashcat-1700000000", valid: true},
+		{name: "session.v2", valid: true},
+		{name: ""},
+		{name: "."},
+		{name: ".."},
+		{name: "../../etc/x"},
+		{name: "a..b"},
+		{name: "dir/session"},
+		{name: `dir\session`},
+		{name: "/tmp/session"},
+	}
+
+	for _, tt := range tests {
+		err := validateSessionName(tt.name)
+		if tt.valid != (err == nil) || (err != nil && !errors.Is(err, ErrInvalidSessionName)) {
+			t.Errorf("validateSessionName(%q) error = %v, want valid %t", tt.name, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package hashcat
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/pixelsquared/go-hashcat/models"
+	"github.com/pixelsquared/go-hashcat/potfile"
+)
+
+// Show returns the hashes of hashFile that are already cracked in a potfile, using
+// hashcat --show. When potfilePath is empty, the client's shared potfile is used, or
+// hashcat's own default potfile if none is configured.
+func (c *HashcatClient) Show(ctx context.Context, hashFile *models.HashFile, potfilePath string) ([]*models.CrackedHash, error) {
+	lines, err := c.runPotfileQuery(ctx, "--show", hashFile, potfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to show cracked hashes: %w", err)
+	}
+
+	results := make([]*models.CrackedHash, 0, len(lines))
+	for _, line := range lines {
+		result, err := parseShowLine(line, hashFile.Username)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cracked hash %q: %w", line, err)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// Left returns the hashes of hashFile that are not cracked in a potfile yet, using
+// hashcat --left. Each hash is returned as a line of the hash file, including the
+// username when hashFile.Username is set.
+func (c *HashcatClient) Left(ctx context.Context, hashFile *models.HashFile, potfilePath string) ([]string, error) {
+	lines, err := c.runPotfileQuery(ctx, "--left", hashFile, potfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list uncracked hashes: %w", err)
+	}
+
+	return lines, nil
+}
+
+// runPotfileQuery runs hashcat in --show or --left mode and returns the lines it prints
+func (c *HashcatClient) runPotfileQuery(ctx context.Context, mode string, hashFile *models.HashFile, potfilePath string) ([]string, error) {
+	if hashFile == nil || hashFile.Path == "" {
+		return nil, ErrInvalidHashFile
+	}
+
+	if potfilePath == "" {
+		potfilePath = c.config.PotfilePath
+	}
+
+	args := []string{
+		"--hash-type", strconv.Itoa(hashFile.HashType),
+		mode,
+		"--quiet",
+	}
+
+	if potfilePath != "" {
+		args = append(args, "--potfile-path", potfilePath)
+	}
+
+	if hashFile.Username {
+		args = append(args, "--username")
+	}
+
+	args = append(args, hashFile.Path)
+
+	// Only stdout holds results, so warnings on stderr are kept out of them
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.config.BinaryPath, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, NewHashcatError(strings.TrimPrefix(mode, "--"), err, strings.TrimSpace(stderr.String()))
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(&stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read hashcat output: %w", err)
+	}
+
+	return lines, nil
+}
+
+// parseShowLine parses a line of --show output, which is a potfile line prefixed
+// with the username when --username is used
+func parseShowLine(line string, username bool) (*models.CrackedHash, error) {
+	entry, err := potfile.ParseLine(line, potfile.DefaultSeparator)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CrackedHash{
+		Hash:     entry.Hash,
+		Password: entry.Plain,
+	}
+
+	if username {
+		user, hash, found := strings.Cut(entry.Hash, string(potfile.DefaultSeparator))
+		if !found {
+			return nil, errors.New("missing username")
+		}
+		result.Username = user
+		result.Hash = hash
+	}
+
+	return result, nil
+}
@@ -0,0 +1,110 @@
+package hashcat
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pixelsquared/go-hashcat/models"
+)
+
+// hashIndex maps the hashes of a hash file to the lines they were read from, so that
+// cracked hashes can be traced back to their accounts
+type hashIndex map[string][]models.HashSource
+
+// buildHashIndex reads a hash file, splitting off the username of each line when
+// username is true
+func buildHashIndex(path string, username bool, separator byte) (hashIndex, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open hash file: %w", err)
+	}
+	defer file.Close()
+
+	index := make(hashIndex)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if line == "" {
+			continue
+		}
+
+		source := models.HashSource{Line: lineNumber, Record: line}
+		hash := line
+
+		if username {
+			user, rest, found := strings.Cut(line, string(separator))
+			if found {
+				source.Username = user
+				hash = rest
+			}
+		}
+
+		key := hashKey(hash, separator)
+		index[key] = append(index[key], source)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read hash file: %w", err)
+	}
+
+	return index, nil
+}
+
+// resolve fills in the sources and usernames of a cracked hash
+func (index hashIndex) resolve(result *models.CrackedHash, separator byte) {
+	sources := index[hashKey(result.Hash, separator)]
+	if len(sources) == 0 {
+		return
+	}
+
+	result.Sources = sources
+	result.Usernames = result.Usernames[:0]
+	for _, source := range sources {
+		if source.Username != "" {
+			result.Usernames = append(result.Usernames, source.Username)
+		}
+	}
+
+	if len(result.Usernames) > 0 {
+		result.Username = result.Usernames[0]
+	}
+}
+
+// hashKey normalizes a hash for lookups, as hashcat may change the case of hex digits
+// when writing cracked hashes. Only a hex digest before the first separator is folded,
+// so salts and formats such as base64 or crypt keep their case and do not collide.
+func hashKey(hash string, separator byte) string {
+	digest, salt, salted := strings.Cut(hash, string(separator))
+	if !isHexString(digest) {
+		return hash
+	}
+
+	if !salted {
+		return strings.ToLower(digest)
+	}
+	return strings.ToLower(digest) + string(separator) + salt
+}
+
+// isHexString reports whether s is made up of hex digits only
+func isHexString(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+
+	return true
+}
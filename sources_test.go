@@ -0,0 +1,51 @@
+package hashcat
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pixelsquared/go-hashcat/models"
+)
+
+func TestHashIndexResolve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashes.txt")
+	lines := "alice:5F4DCC3B5AA765D61D8327DEB882CF99\n" +
+		"bob:5f4dcc3b5aa765d61d8327deb882cf99\n" +
+		"carol:A1B2C3:SaltAB\n" +
+		"dave:$2a$10$AbCdEf\n"
+	if err := os.WriteFile(path, []byte(lines), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := buildHashIndex(path, true, ':')
+	if err != nil {
+		t.Fatalf("buildHashIndex() error = %v", err)
+	}
+
+	tests := []struct {
+		hash      string
+		usernames []string
+	}{
+		{hash: "5f4dcc3b5aa765d61d8327deb882cf99", usernames: []string{"alice", "bob"}},
+		{hash: "5F4DCC3B5AA765D61D8327DEB882CF99", usernames: []string{"alice", "bob"}},
+		{hash: "a1b2c3:SaltAB", usernames: []string{"carol"}},
+		{hash: "A1B2C3:SaltAB", usernames: []string{"carol"}},
+		{hash: "a1b2c3:saltab"},
+		{hash: "$2a$10$AbCdEf", usernames: []string{"dave"}},
+		{hash: "$2a$10$abcdef"},
+	}
+
+	for _, tt := range tests {
+		result := &models.CrackedHash{Hash: tt.hash}
+		index.resolve(result, ':')
+
+		if len(result.Usernames) == 0 && len(tt.usernames) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(result.Usernames, tt.usernames) {
+			t.Errorf("resolve(%q) usernames = %v, want %v", tt.hash, result.Usernames, tt.usernames)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package hashcat
+
+import (
+	"context"
+	"syscall"
+	"time"
+
+	"github.com/pixelsquared/go-hashcat/models"
+)
+
+// stopGracePeriod is how long hashcat is given to exit after SIGTERM before it is killed
+const stopGracePeriod = 5 * time.Second
+
+// StopResult describes a session after it has been shut down
+type StopResult struct {
+	Results     []*models.CrackedHash // Every hash cracked by the session, including the final drain of the outfile
+	SessionName string                // Name to pass to RestoreSession
+	Restorable  bool                  // Whether hashcat left a restore file behind
+	Forced      bool                  // Whether hashcat had to be terminated with a signal
+}
+
+// Shutdown asks hashcat to checkpoint and quit, so that no work is lost and the session
+// can be restored later. If hashcat has not exited when ctx is done, it is sent SIGTERM
+// and, after a short grace period, killed. The outfile is drained before the results
+// are returned, and files needed to restore the session are kept.
+func (s *HashcatCrackSession) Shutdown(ctx context.Context) (*StopResult, error) {
+	s.mutex.Lock()
+	exited := s.exited
+	running := s.isRunning
+	s.mutex.Unlock()
+
+	// Ask hashcat to quit at the next restore point, without waiting past ctx for a
+	// hashcat that does not read its prompt
+	var keyErr error
+	if running {
+		keyErr = s.sendKeyContext(ctx, keyCheckpoint)
+		if keyErr == nil {
+			s.setStatus(models.StatusRunningCheckpointQuit)
+		}
+	}
+
+	result := &StopResult{SessionName: s.sessionName}
+
+	// Stop blocking on a lossless progress reader, which may be the caller itself
+	s.progressFeed.release()
+
+	// The session was never started, so there is nothing to wait for
+	if exited == nil {
+		s.cancel()
+		s.cleanup()
+		s.events.close()
+		return result, nil
+	}
+
+	// Without a prompt to talk to, go straight to terminating the process
+	if keyErr != nil {
+		ctx = canceledContext()
+	}
+
+	var err error
+	result.Forced, err = s.awaitExit(ctx, exited)
+
+	// Wait for the output to be drained
+	s.wg.Wait()
+	s.cancel()
+
+	s.mutex.Lock()
+	result.Results = append([]*models.CrackedHash(nil), s.results...)
+	s.mutex.Unlock()
+
+	result.Restorable = s.Restorable()
+	if !result.Restorable {
+		s.cleanup()
+	}
+
+	return result, err
+}
+
+// awaitExit waits for hashcat to exit until ctx is done, then escalates to SIGTERM and
+// SIGKILL. It reports whether a signal had to be sent.
+func (s *HashcatCrackSession) awaitExit(ctx context.Context, exited <-chan struct{}) (bool, error) {
+	select {
+	case <-exited:
+		return false, nil
+	case <-ctx.Done():
+	}
+
+	// Give hashcat a chance to write its restore file before killing it
+	if err := s.cmd.Process.Signal(syscall.SIGTERM); err == nil {
+		timer := time.NewTimer(stopGracePeriod)
+		defer timer.Stop()
+
+		select {
+		case <-exited:
+			return true, nil
+		case <-timer.C:
+		}
+	}
+
+	if err := s.cmd.Process.Kill(); err != nil {
+		select {
+		case <-exited:
+			// The process exited in the meantime
+			return true, nil
+		default:
+			return true, err
+		}
+	}
+
+	<-exited
+	return true, nil
+}
+
+// canceledContext returns a context that is already done
+func canceledContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
@@ -0,0 +1,230 @@
+package hashcat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// tailPollInterval is how often the outfile is checked for appended lines when file
+// change notifications are unavailable
+const tailPollInterval = 500 * time.Millisecond
+
+// changeNotifier signals that a followed file may have changed. Signals are coalesced,
+// so a single receive can stand for several changes.
+type changeNotifier interface {
+	// Changes returns the channel that receives a value after the file changes
+	Changes() <-chan struct{}
+
+	// Close stops watching the file
+	Close() error
+}
+
+// pollNotifier signals a possible change at a fixed interval, for platforms and file
+// systems without change notifications
+type pollNotifier struct {
+	changes chan struct{}
+	stop    chan struct{}
+}
+
+// newPollNotifier returns a notifier that signals every interval
+func newPollNotifier(interval time.Duration) *pollNotifier {
+	n := &pollNotifier{
+		changes: make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				n.signal()
+			case <-n.stop:
+				return
+			}
+		}
+	}()
+
+	return n
+}
+
+// signal reports a possible change unless one is already pending
+func (n *pollNotifier) signal() {
+	select {
+	case n.changes <- struct{}{}:
+	default:
+	}
+}
+
+// Changes returns the channel that receives a value every interval
+func (n *pollNotifier) Changes() <-chan struct{} {
+	return n.changes
+}
+
+// Close stops polling
+func (n *pollNotifier) Close() error {
+	close(n.stop)
+	return nil
+}
+
+// outfileTailer follows a file that hashcat appends lines to. It keeps the file open and
+// only reads what was appended since the last read, holding back a trailing line until
+// its newline has been written.
+type outfileTailer struct {
+	path    string
+	file    *os.File
+	offset  int64  // Bytes of the file consumed so far, including any partial line
+	partial []byte // Trailing bytes not yet terminated by a newline
+}
+
+// newOutfileTailer returns a tailer for the file at path, which does not have to exist yet
+func newOutfileTailer(path string) *outfileTailer {
+	return &outfileTailer{path: path}
+}
+
+// Follow calls handle for each line appended to the file until done is closed. It then
+// reads the file one last time, handling a trailing line even without its newline, so
+// that every line written before done was closed is handled. Read errors do not stop the
+// tailer; the first one is returned once it is done.
+func (t *outfileTailer) Follow(done <-chan struct{}, handle func(line string)) error {
+	defer t.close()
+
+	// Watch before the first read so no change is missed in between
+	notifier := newChangeNotifier(t.path)
+	defer notifier.Close()
+	changes := notifier.Changes()
+
+	var firstErr error
+	record := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	record(t.drain(handle))
+
+	for {
+		select {
+		case <-changes:
+			record(t.drain(handle))
+
+		case <-done:
+			record(t.drain(handle))
+			t.flush(handle)
+			return firstErr
+		}
+	}
+}
+
+// drain reads everything appended since the last read and handles the complete lines
+func (t *outfileTailer) drain(handle func(line string)) error {
+	if err := t.open(); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			// hashcat creates the outfile on the first crack
+			return nil
+		}
+		return fmt.Errorf("error opening output file: %w", err)
+	}
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := t.file.Read(buf)
+		if n > 0 {
+			t.offset += int64(n)
+			t.consume(buf[:n], handle)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading output file: %w", err)
+		}
+	}
+}
+
+// open opens the file if needed. The file is reopened from the start when it has been
+// replaced, and reread from the start when it has been truncated.
+func (t *outfileTailer) open() error {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return err
+	}
+
+	if t.file != nil {
+		current, err := t.file.Stat()
+		if err == nil && os.SameFile(info, current) {
+			if info.Size() >= t.offset {
+				return nil
+			}
+
+			// Truncated: everything left is new
+			t.offset = 0
+			t.partial = nil
+			_, err := t.file.Seek(0, io.SeekStart)
+			return err
+		}
+		t.close()
+	}
+
+	file, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+
+	t.file = file
+	t.offset = 0
+	t.partial = nil
+
+	return nil
+}
+
+// consume splits newly read bytes into lines, keeping a trailing partial line for later
+func (t *outfileTailer) consume(data []byte, handle func(line string)) {
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			t.partial = append(t.partial, data...)
+			return
+		}
+
+		line := data[:i]
+		if len(t.partial) > 0 {
+			line = append(t.partial, line...)
+			t.partial = nil
+		}
+		t.emit(line, handle)
+
+		data = data[i+1:]
+	}
+}
+
+// flush handles a trailing line that was never terminated by a newline
+func (t *outfileTailer) flush(handle func(line string)) {
+	if len(t.partial) > 0 {
+		t.emit(t.partial, handle)
+		t.partial = nil
+	}
+}
+
+// emit handles a line, skipping empty ones
+func (t *outfileTailer) emit(line []byte, handle func(line string)) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) > 0 {
+		handle(string(line))
+	}
+}
+
+// close closes the file if it is open
+func (t *outfileTailer) close() {
+	if t.file != nil {
+		t.file.Close()
+		t.file = nil
+	}
+}
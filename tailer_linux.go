@@ -0,0 +1,85 @@
+//go:build linux
+
+package hashcat
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// inotifySafetyInterval is how often the outfile is checked even when inotify reports
+// nothing, for file systems that do not deliver change events
+const inotifySafetyInterval = 5 * time.Second
+
+// inotifyNotifier signals changes to a file reported by inotify. The directory is watched
+// rather than the file, so the file may be created or replaced after watching starts.
+type inotifyNotifier struct {
+	*pollNotifier
+	file *os.File
+	name string
+}
+
+// newChangeNotifier returns a notifier for the file at path, using inotify and falling
+// back to polling when inotify is unavailable
+func newChangeNotifier(path string) changeNotifier {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return newPollNotifier(tailPollInterval)
+	}
+
+	mask := uint32(syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE | syscall.IN_CREATE | syscall.IN_MOVED_TO)
+	if _, err := syscall.InotifyAddWatch(fd, filepath.Dir(path), mask); err != nil {
+		syscall.Close(fd)
+		return newPollNotifier(tailPollInterval)
+	}
+
+	// A non-blocking descriptor is read through the runtime poller, so closing the
+	// file unblocks a pending read
+	n := &inotifyNotifier{
+		pollNotifier: newPollNotifier(inotifySafetyInterval),
+		file:         os.NewFile(uintptr(fd), "inotify"),
+		name:         filepath.Base(path),
+	}
+	go n.run()
+
+	return n
+}
+
+// run reads inotify events until the notifier is closed, signaling events for the file
+func (n *inotifyNotifier) run() {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+
+	for {
+		count, err := n.file.Read(buf)
+		if err != nil {
+			// Closed, or inotify failed; the safety poll keeps the tailer going
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= count; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			nameEnd := nameStart + int(event.Len)
+			if nameEnd > count {
+				break
+			}
+
+			name := string(bytes.TrimRight(buf[nameStart:nameEnd], "\x00"))
+			if name == n.name || event.Mask&syscall.IN_Q_OVERFLOW != 0 {
+				n.signal()
+			}
+
+			offset = nameEnd
+		}
+	}
+}
+
+// Close stops watching the file
+func (n *inotifyNotifier) Close() error {
+	n.pollNotifier.Close()
+	return n.file.Close()
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package hashcat
+
+// newChangeNotifier returns a notifier for the file at path. File change notifications
+// are only used on Linux; other platforms poll.
+func newChangeNotifier(path string) changeNotifier {
+	return newPollNotifier(tailPollInterval)
+}
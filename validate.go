@@ -0,0 +1,265 @@
+package hashcat
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// maxHashLineErrors is the number of invalid lines reported for a hash file before the
+// remaining ones are only counted
+const maxHashLineErrors = 100
+
+// HashValidator checks the format of a single hash, returning an error describing why it
+// is invalid. The hash is given without any username.
+type HashValidator func(hash string) error
+
+var (
+	validatorsMu   sync.RWMutex
+	hashValidators = map[int]HashValidator{}
+)
+
+// RegisterHashValidator sets the validator used for a hash mode, replacing any existing one
+func RegisterHashValidator(hashType int, validator HashValidator) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+
+	if validator == nil {
+		delete(hashValidators, hashType)
+		return
+	}
+	hashValidators[hashType] = validator
+}
+
+// LookupHashValidator returns the validator registered for a hash mode
+func LookupHashValidator(hashType int) (HashValidator, bool) {
+	validatorsMu.RLock()
+	defer validatorsMu.RUnlock()
+
+	validator, ok := hashValidators[hashType]
+	return validator, ok
+}
+
+// PatternValidator returns a validator that accepts hashes matching any of the patterns
+func PatternValidator(name string, patterns ...string) HashValidator {
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		compiled[i] = regexp.MustCompile(pattern)
+	}
+
+	return func(hash string) error {
+		for _, pattern := range compiled {
+			if pattern.MatchString(hash) {
+				return nil
+			}
+		}
+		return fmt.Errorf("not a valid %s hash", name)
+	}
+}
+
+// HashLineError reports an invalid hash
+type HashLineError struct {
+	Line int    // 1-based line number in the hash file
+	Hash string // The offending line
+	Err  error  // The underlying error, which wraps ErrInvalidHash
+}
+
+// Error implements the error interface
+func (e *HashLineError) Error() string {
+	if e.Line == 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap implements the error unwrapping interface
+func (e *HashLineError) Unwrap() error {
+	return e.Err
+}
+
+// HashFileError reports the invalid lines of a hash file
+type HashFileError struct {
+	Path    string           // The hash file
+	Lines   []*HashLineError // The first invalid lines
+	Invalid int              // Total number of invalid lines
+}
+
+// Error implements the error interface
+func (e *HashFileError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(ErrInvalidHashFile.Error())
+	if e.Path != "" {
+		sb.WriteString(" " + e.Path)
+	}
+	fmt.Fprintf(&sb, ": %d invalid hashes", e.Invalid)
+
+	for _, line := range e.Lines {
+		sb.WriteString("\n  ")
+		sb.WriteString(line.Error())
+	}
+
+	if e.Invalid > len(e.Lines) {
+		fmt.Fprintf(&sb, "\n  ... and %d more", e.Invalid-len(e.Lines))
+	}
+
+	return sb.String()
+}
+
+// Unwrap implements the error unwrapping interface, so that both ErrInvalidHashFile and
+// the errors of each line can be matched
+func (e *HashFileError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Lines)+1)
+	errs = append(errs, ErrInvalidHashFile)
+	for _, line := range e.Lines {
+		errs = append(errs, line)
+	}
+	return errs
+}
+
+// ValidateHash checks a hash against the validator registered for its mode. When username
+// is true the hash is prefixed with a username, as with --username. Hashes of modes without
+// a validator are accepted.
+func ValidateHash(hashType int, hash string, username bool) error {
+	validator, ok := LookupHashValidator(hashType)
+	if !ok {
+		return nil
+	}
+
+	if username {
+		_, rest, found := strings.Cut(hash, ":")
+		if !found {
+			return fmt.Errorf("%w: missing username", ErrInvalidHash)
+		}
+		hash = rest
+	}
+
+	if err := validator(hash); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+
+	return nil
+}
+
+// ValidateHashes checks each line read from r, skipping empty lines like hashcat does.
+// It returns a *HashFileError listing the invalid lines.
+func ValidateHashes(r io.Reader, hashType int, username bool) error {
+	if _, ok := LookupHashValidator(hashType); !ok {
+		return nil
+	}
+
+	fileErr := &HashFileError{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if line == "" {
+			continue
+		}
+
+		if err := ValidateHash(hashType, line, username); err != nil {
+			fileErr.Invalid++
+			if len(fileErr.Lines) < maxHashLineErrors {
+				fileErr.Lines = append(fileErr.Lines, &HashLineError{Line: lineNumber, Hash: line, Err: err})
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidHashFile, err)
+	}
+
+	if fileErr.Invalid > 0 {
+		return fileErr
+	}
+
+	return nil
+}
+
+// ValidateHashFile checks each line of a hash file
+func ValidateHashFile(path string, hashType int, username bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidHashFile, err)
+	}
+	defer file.Close()
+
+	if err := ValidateHashes(file, hashType, username); err != nil {
+		var fileErr *HashFileError
+		if errors.As(err, &fileErr) {
+			fileErr.Path = path
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Patterns shared by several hash modes
+const (
+	hex16  = `^[0-9a-fA-F]{16}$`
+	hex32  = `^[0-9a-fA-F]{32}$`
+	hex40  = `^[0-9a-fA-F]{40}$`
+	hex56  = `^[0-9a-fA-F]{56}$`
+	hex64  = `^[0-9a-fA-F]{64}$`
+	hex96  = `^[0-9a-fA-F]{96}$`
+	hex128 = `^[0-9a-fA-F]{128}$`
+)
+
+func init() {
+	// Raw hashes
+	RegisterHashValidator(0, PatternValidator("MD5", hex32))
+	RegisterHashValidator(900, PatternValidator("MD4", hex32))
+	RegisterHashValidator(1000, PatternValidator("NTLM", hex32))
+	RegisterHashValidator(100, PatternValidator("SHA1", hex40))
+	RegisterHashValidator(6000, PatternValidator("RIPEMD-160", hex40))
+	RegisterHashValidator(1300, PatternValidator("SHA2-224", hex56))
+	RegisterHashValidator(17300, PatternValidator("SHA3-224", hex56))
+	RegisterHashValidator(1400, PatternValidator("SHA2-256", hex64))
+	RegisterHashValidator(17400, PatternValidator("SHA3-256", hex64))
+	RegisterHashValidator(17800, PatternValidator("Keccak-256", hex64))
+	RegisterHashValidator(10800, PatternValidator("SHA2-384", hex96))
+	RegisterHashValidator(17500, PatternValidator("SHA3-384", hex96))
+	RegisterHashValidator(1700, PatternValidator("SHA2-512", hex128))
+	RegisterHashValidator(17600, PatternValidator("SHA3-512", hex128))
+	RegisterHashValidator(6100, PatternValidator("Whirlpool", hex128))
+	RegisterHashValidator(3000, PatternValidator("LM", hex16, hex32))
+	RegisterHashValidator(200, PatternValidator("MySQL323", hex16))
+	RegisterHashValidator(300, PatternValidator("MySQL4.1/MySQL5", `^\*?[0-9a-fA-F]{40}$`))
+
+	// Salted raw hashes
+	RegisterHashValidator(10, PatternValidator("md5($pass.$salt)", `^[0-9a-fA-F]{32}:.*$`))
+	RegisterHashValidator(20, PatternValidator("md5($salt.$pass)", `^[0-9a-fA-F]{32}:.*$`))
+	RegisterHashValidator(110, PatternValidator("sha1($pass.$salt)", `^[0-9a-fA-F]{40}:.*$`))
+	RegisterHashValidator(120, PatternValidator("sha1($salt.$pass)", `^[0-9a-fA-F]{40}:.*$`))
+
+	// Unix crypt and web application formats
+	RegisterHashValidator(3200, PatternValidator("bcrypt", `^\$2[abxy]?\$\d{2}\$[./A-Za-z0-9]{53}$`))
+	RegisterHashValidator(1800, PatternValidator("sha512crypt", `^\$6\$(rounds=\d+\$)?[^$]{0,16}\$[./A-Za-z0-9]{86}$`))
+	RegisterHashValidator(7400, PatternValidator("sha256crypt", `^\$5\$(rounds=\d+\$)?[^$]{0,16}\$[./A-Za-z0-9]{43}$`))
+	RegisterHashValidator(500, PatternValidator("md5crypt", `^\$1\$[^$]{0,8}\$[./A-Za-z0-9]{22}$`))
+	RegisterHashValidator(1600, PatternValidator("Apache MD5", `^\$apr1\$[^$]{0,8}\$[./A-Za-z0-9]{22}$`))
+	RegisterHashValidator(400, PatternValidator("phpass", `^\$[PH]\$[./A-Za-z0-9]{31}$`))
+	RegisterHashValidator(2100, PatternValidator("DCC2", `^\$DCC2\$\d+#[^#]+#[0-9a-fA-F]{32}$`))
+
+	// Network protocols
+	RegisterHashValidator(5500, PatternValidator("NetNTLMv1", `^[^:]*::[^:]*:[0-9a-fA-F]{48}:[0-9a-fA-F]{48}:[0-9a-fA-F]{16}$`))
+	RegisterHashValidator(5600, PatternValidator("NetNTLMv2", `^[^:]*::[^:]*:[0-9a-fA-F]{16}:[0-9a-fA-F]{32}:[0-9a-fA-F]+$`))
+	RegisterHashValidator(13100, PatternValidator("Kerberos 5 TGS-REP etype 23", `^\$krb5tgs\$23\$(\*[^*]*\*\$)?[0-9a-fA-F]{32}\$[0-9a-fA-F]+$`))
+	RegisterHashValidator(19600, PatternValidator("Kerberos 5 TGS-REP etype 17", `^\$krb5tgs\$17\$[^$]+\$[^$]+\$(\*[^*]*\*\$)?[0-9a-fA-F]{24}\$[0-9a-fA-F]+$`))
+	RegisterHashValidator(19700, PatternValidator("Kerberos 5 TGS-REP etype 18", `^\$krb5tgs\$18\$[^$]+\$[^$]+\$(\*[^*]*\*\$)?[0-9a-fA-F]{24}\$[0-9a-fA-F]+$`))
+	RegisterHashValidator(18200, PatternValidator("Kerberos 5 AS-REP etype 23", `^\$krb5asrep\$23\$([^:$]+[:$])?[0-9a-fA-F]{32}\$[0-9a-fA-F]+$`))
+	RegisterHashValidator(7500, PatternValidator("Kerberos 5 AS-REQ Pre-Auth etype 23", `^\$krb5pa\$23\$[^$]*\$[^$]*\$[^$]*\$[0-9a-fA-F]{104}$`))
+	RegisterHashValidator(22000, PatternValidator("WPA-PBKDF2-PMKID+EAPOL",
+		`^WPA\*0[12]\*[0-9a-fA-F]{32}\*[0-9a-fA-F]{12}\*[0-9a-fA-F]{12}\*[0-9a-fA-F]*\*[0-9a-fA-F]*\*[0-9a-fA-F]*\*[0-9a-fA-F]*$`))
+	RegisterHashValidator(16800, PatternValidator("WPA-PMKID-PBKDF2", `^[0-9a-fA-F]{32}\*[0-9a-fA-F]{12}\*[0-9a-fA-F]{12}\*[0-9a-fA-F]*$`))
+}